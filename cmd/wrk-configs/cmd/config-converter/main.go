@@ -0,0 +1,164 @@
+package main
+
+// Конвертер конфигурационных файлов между JSON, YAML, INI и TOML: main.go
+// go run ./cmd/wrk-configs/cmd/config-converter --to yaml cmd/wrk-configs/configs/examples/app.json
+// cat app.json | go run ./cmd/wrk-configs/cmd/config-converter --from json --to toml
+// go run ./cmd/wrk-configs/cmd/config-converter -o /tmp/app.ini cmd/wrk-configs/configs/examples/test_config.json
+// При выводе в stdout пароли, секреты и токены скрываются, если не указан --show-secrets
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/generators"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/manager"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/parsers"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/secrets"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/types"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/utils"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// stdio обозначает stdin/stdout вместо файла
+const stdio = "-"
+
+func main() {
+	app := cli.NewApp()
+	app.Name = "config-converter"
+	app.Usage = "Convert configuration files between JSON, YAML, INI and TOML"
+	app.ArgsUsage = "[input file or - for stdin]"
+	app.Flags = []cli.Flag{
+		cli.StringFlag{
+			Name:  "from, f",
+			Usage: "Input format (json, yaml, ini, toml); detected from extension or content if omitted",
+		},
+		cli.StringFlag{
+			Name:  "to, t",
+			Usage: "Output format; taken from the output file extension if omitted",
+		},
+		cli.StringFlag{
+			Name:  "output, o",
+			Value: stdio,
+			Usage: "Output file, - for stdout",
+		},
+		cli.BoolFlag{
+			Name:  "strict",
+			Usage: "Fail instead of warning when the conversion loses data",
+		},
+		cli.BoolFlag{
+			Name:  "show-secrets",
+			Usage: "Do not redact passwords, secrets and tokens when writing to stdout",
+		},
+	}
+	app.Action = convert
+
+	if err := app.Run(os.Args); err != nil {
+		os.Exit(1)
+	}
+}
+
+// convert выполняет конвертацию согласно флагам
+func convert(c *cli.Context) error {
+	input := c.Args().First()
+	if input == "" {
+		input = stdio
+	}
+	output := c.String("output")
+
+	data, err := readInput(input)
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+
+	srcFormat, err := sourceFormat(c.String("from"), input, data)
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+	dstFormat, err := targetFormat(c.String("to"), output)
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+
+	value, err := parsers.ParseDynamic(srcFormat, data)
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("не удалось распарсить %s: %v", input, err), 1)
+	}
+
+	losses := generators.CheckLossy(value, dstFormat)
+	for _, loss := range losses {
+		fmt.Fprintf(os.Stderr, "предупреждение: %s\n", loss)
+	}
+	if len(losses) > 0 && c.Bool("strict") {
+		return cli.NewExitError(fmt.Sprintf("конвертация %s -> %s с потерями (%d), --strict", srcFormat, dstFormat, len(losses)), 1)
+	}
+
+	cm := manager.NewConfigManager()
+
+	if input != stdio && output != stdio && c.String("from") == "" {
+		if err := cm.Convert(input, output, dstFormat); err != nil {
+			return cli.NewExitError(err.Error(), 1)
+		}
+		return nil
+	}
+
+	var out []byte
+	if output == stdio && !c.Bool("show-secrets") {
+		out, err = generators.Generate(dstFormat, secrets.Redact(value))
+	} else {
+		out, err = cm.ConvertData(data, srcFormat, dstFormat)
+	}
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+
+	if output == stdio {
+		_, err = os.Stdout.Write(out)
+	} else {
+		err = os.WriteFile(output, out, 0o644)
+	}
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+	return nil
+}
+
+// readInput читает файл или stdin
+func readInput(input string) ([]byte, error) {
+	if input == stdio {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(input)
+}
+
+// sourceFormat определяет формат входных данных
+func sourceFormat(flag, input string, data []byte) (types.ConfigFormat, error) {
+	if flag != "" {
+		format := parsers.ParseFormat(flag)
+		_, err := parsers.Get(format)
+		return format, err
+	}
+	if input == stdio {
+		if format := utils.GetFormatByContent(data); format != "" {
+			return format, nil
+		}
+		return "", fmt.Errorf("не удалось определить формат stdin, укажите --from")
+	}
+	return parsers.DetectFormat(input, data)
+}
+
+// targetFormat определяет формат результата
+func targetFormat(flag, output string) (types.ConfigFormat, error) {
+	if flag != "" {
+		format := parsers.ParseFormat(flag)
+		_, err := generators.Get(format)
+		return format, err
+	}
+	if output != stdio {
+		if format := utils.GetFormatByExtension(filepath.Ext(output)); format != "" {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("не удалось определить выходной формат, укажите --to")
+}
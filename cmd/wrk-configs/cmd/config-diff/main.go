@@ -0,0 +1,111 @@
+package main
+
+// Смысловое сравнение конфигурационных файлов любых форматов: main.go
+// go run ./cmd/wrk-configs/cmd/config-diff cmd/wrk-configs/configs/examples/app.json cmd/wrk-configs/configs/examples/app.yml
+// go run ./cmd/wrk-configs/cmd/config-diff -o json app.json app.toml
+// go run ./cmd/wrk-configs/cmd/config-diff -o patch --show-secrets app.json app.prod.json > app.patch.json
+// Код выхода: 0 - различий нет, 1 - есть различия, 2 - ошибка.
+// Значения паролей, секретов и токенов скрываются, если не указан --show-secrets;
+// -o patch с такими значениями требует --show-secrets, патч никогда не скрывается
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/diff"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/parsers"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/types"
+	"gopkg.in/urfave/cli.v1"
+)
+
+func main() {
+	app := cli.NewApp()
+	app.Name = "config-diff"
+	app.Usage = "Show semantic differences between two configuration files in any formats"
+	app.ArgsUsage = "<old file> <new file>"
+	app.Flags = []cli.Flag{
+		cli.StringFlag{
+			Name:  "output, o",
+			Value: "text",
+			Usage: "Output: text, json or patch (JSON Patch, RFC 6902)",
+		},
+		cli.BoolFlag{
+			Name:  "loose",
+			Usage: "Compare strings with other types by value (\"8080\" == 8080); always on for INI",
+		},
+		cli.BoolFlag{
+			Name:  "show-secrets",
+			Usage: "Do not redact passwords, secrets and tokens",
+		},
+	}
+	app.Action = compare
+
+	// Ошибки cli.ExitError завершают процесс со своим кодом внутри Run
+	if err := app.Run(os.Args); err != nil {
+		os.Exit(2)
+	}
+}
+
+// compare сравнивает файлы и выводит различия в выбранном виде
+func compare(c *cli.Context) error {
+	if c.NArg() != 2 {
+		return cli.NewExitError("укажите два конфигурационных файла", 2)
+	}
+
+	old, oldFormat, err := parsers.LoadDynamicFile(c.Args().Get(0))
+	if err != nil {
+		return cli.NewExitError(err.Error(), 2)
+	}
+	updated, newFormat, err := parsers.LoadDynamicFile(c.Args().Get(1))
+	if err != nil {
+		return cli.NewExitError(err.Error(), 2)
+	}
+
+	d := diff.NewDiffer()
+	d.Loose = c.Bool("loose") || oldFormat == types.FormatINI || newFormat == types.FormatINI
+	changes := d.Compare(old, updated)
+
+	// Вывод для чтения скрывает секреты, а патч всегда содержит настоящие
+	// значения, поэтому для патча с секретами нужен явный --show-secrets
+	shown := changes
+	if !c.Bool("show-secrets") {
+		shown = diff.Redact(changes)
+	}
+
+	switch c.String("output") {
+	case "text":
+		fmt.Print(diff.Text(shown))
+	case "json":
+		if err := printJSON(shown); err != nil {
+			return cli.NewExitError(err.Error(), 2)
+		}
+	case "patch":
+		if !c.Bool("show-secrets") && diff.HasSecrets(changes) {
+			return cli.NewExitError("патч содержит пароли, секреты или токены; укажите --show-secrets", 2)
+		}
+		ops, err := diff.Patch(changes)
+		if err != nil {
+			return cli.NewExitError(err.Error(), 2)
+		}
+		if err := printJSON(ops); err != nil {
+			return cli.NewExitError(err.Error(), 2)
+		}
+	default:
+		return cli.NewExitError(fmt.Sprintf("неизвестный вывод %q: text, json или patch", c.String("output")), 2)
+	}
+
+	if len(changes) > 0 {
+		return cli.NewExitError("", 1)
+	}
+	return nil
+}
+
+func printJSON(v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+	return nil
+}
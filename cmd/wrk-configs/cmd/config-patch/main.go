@@ -0,0 +1,139 @@
+package main
+
+// Применение JSON Patch (RFC 6902) и JSON Merge Patch (RFC 7386) к конфигурациям: main.go
+// go run ./cmd/wrk-configs/cmd/config-patch --dry-run cmd/wrk-configs/configs/examples/app.yml app.patch.json
+// go run ./cmd/wrk-configs/cmd/config-patch app.toml prod.merge.yml
+// echo '[{"op":"test","path":"/server/port","value":8080},{"op":"replace","path":"/server/port","value":80}]' | go run ./cmd/wrk-configs/cmd/config-patch app.ini -
+// Массив операций JSON - JSON Patch, объект любого формата - Merge Patch.
+// Файл записывается в исходном формате; если ключи не удалялись,
+// комментарии и порядок ключей YAML, INI и TOML сохраняются
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/diff"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/parsers"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/patch"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/reader"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/types"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/utils"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// stdio обозначает stdin вместо файла патча
+const stdio = "-"
+
+func main() {
+	app := cli.NewApp()
+	app.Name = "config-patch"
+	app.Usage = "Apply a JSON Patch or JSON Merge Patch to a JSON, YAML, INI or TOML configuration"
+	app.ArgsUsage = "<config file> <patch file or - for stdin>"
+	app.Flags = []cli.Flag{
+		cli.BoolFlag{
+			Name:  "merge, m",
+			Usage: "Treat the patch as a JSON Merge Patch (RFC 7386) even if it looks like a JSON Patch",
+		},
+		cli.BoolFlag{
+			Name:  "dry-run, n",
+			Usage: "Show the resulting differences without writing the file",
+		},
+		cli.StringFlag{
+			Name:  "output, o",
+			Usage: "Write the result to another file; its format is taken from the extension",
+		},
+		cli.BoolFlag{
+			Name:  "show-secrets",
+			Usage: "Do not redact passwords, secrets and tokens in the printed differences",
+		},
+	}
+	app.Action = apply
+
+	if err := app.Run(os.Args); err != nil {
+		os.Exit(1)
+	}
+}
+
+// apply загружает конфигурацию и патч, применяет его и сохраняет результат
+func apply(c *cli.Context) error {
+	if c.NArg() != 2 {
+		return cli.NewExitError("укажите конфигурационный файл и файл патча", 2)
+	}
+	configPath, patchPath := c.Args().Get(0), c.Args().Get(1)
+
+	cr := reader.NewConfigReader()
+	if err := cr.ReadFile(configPath); err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+
+	data, err := readPatch(patchPath)
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+
+	format, err := patchFormat(patchPath, data)
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("%s: %v", patchPath, err), 1)
+	}
+
+	// JSON Patch - только JSON с массивом на верхнем уровне: заголовки
+	// [section] INI и TOML тоже начинаются с "["
+	var changes []diff.Change
+	if !c.Bool("merge") && format == types.FormatJSON && bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		ops, err := patch.Decode(data)
+		if err != nil {
+			return cli.NewExitError(fmt.Sprintf("%s: %v", patchPath, err), 1)
+		}
+		if changes, err = cr.ApplyPatch(ops); err != nil {
+			return cli.NewExitError(fmt.Sprintf("патч не применен: %v", err), 1)
+		}
+	} else {
+		mp, err := parsers.ParseDynamic(format, data)
+		if err != nil {
+			return cli.NewExitError(fmt.Sprintf("%s: %v", patchPath, err), 1)
+		}
+		changes = cr.ApplyMergePatch(mp)
+	}
+
+	if c.Bool("show-secrets") {
+		fmt.Print(diff.Text(changes))
+	} else {
+		fmt.Print(diff.Text(diff.Redact(changes)))
+	}
+
+	if c.Bool("dry-run") {
+		fmt.Fprintf(os.Stderr, "пробный прогон: %d изменений, файл не записан\n", len(changes))
+		return nil
+	}
+
+	if output := c.String("output"); output != "" {
+		err = cr.SaveAs(output, "")
+	} else if len(changes) > 0 {
+		err = cr.Save()
+	}
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+	return nil
+}
+
+// readPatch читает файл патча или stdin
+func readPatch(path string) ([]byte, error) {
+	if path == stdio {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(path)
+}
+
+// patchFormat определяет формат патча по расширению или, для stdin, по содержимому
+func patchFormat(path string, data []byte) (types.ConfigFormat, error) {
+	if path != stdio {
+		return parsers.DetectFormat(path, data)
+	}
+	if format := utils.GetFormatByContent(data); format != "" {
+		return format, nil
+	}
+	return "", fmt.Errorf("не удалось определить формат патча")
+}
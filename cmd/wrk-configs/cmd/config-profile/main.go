@@ -0,0 +1,146 @@
+package main
+
+// Эффективная конфигурация с наложенным профилем: main.go
+// go run ./cmd/wrk-configs/cmd/config-profile --profile production cmd/wrk-configs/configs/examples/test_config.json
+// APP_ENV=production go run ./cmd/wrk-configs/cmd/config-profile --to yaml cmd/wrk-configs/configs/examples/test_config.json
+// go run ./cmd/wrk-configs/cmd/config-profile -p production --provenance cmd/wrk-configs/configs/examples/test_config.json
+// Профиль берется из --profile, затем из APP_ENV, затем из ключа "environment" файла.
+// Пароли, секреты и токены скрываются, если не указан --show-secrets
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/generators"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/include"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/interpolate"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/parsers"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/profile"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/secrets"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/types"
+	"gopkg.in/urfave/cli.v1"
+)
+
+func main() {
+	app := cli.NewApp()
+	app.Name = "config-profile"
+	app.Usage = "Print the effective configuration for a profile (base file + profile overlays)"
+	app.ArgsUsage = "<config file>"
+	app.Flags = []cli.Flag{
+		cli.StringFlag{
+			Name:  "profile, p",
+			Usage: "Profile name; taken from $" + profile.EnvVar + " or the \"environment\" key if omitted",
+		},
+		cli.StringFlag{
+			Name:  "to, t",
+			Usage: "Output format (json, yaml, ini, toml); the input format if omitted",
+		},
+		cli.BoolFlag{
+			Name:  "provenance",
+			Usage: "Print the source of every value instead of the configuration",
+		},
+		cli.BoolFlag{
+			Name:  "interpolate, i",
+			Usage: "Expand ${key}, ${env:...} and ${file:...} references",
+		},
+		cli.StringFlag{
+			Name:   "key-file, k",
+			EnvVar: secrets.KeyFileEnv,
+			Usage:  "Key file for decrypting ENC[...] values",
+		},
+		cli.BoolFlag{
+			Name:  "show-secrets",
+			Usage: "Do not redact passwords, secrets and tokens",
+		},
+	}
+	app.Action = show
+
+	if err := app.Run(os.Args); err != nil {
+		os.Exit(1)
+	}
+}
+
+// show загружает файл с профилем и выводит результат
+func show(c *cli.Context) error {
+	path := c.Args().First()
+	if path == "" {
+		return cli.NewExitError("укажите конфигурационный файл", 2)
+	}
+
+	loader := profile.NewLoader()
+	loader.Profile = c.String("profile")
+	loader.Load = func(path string) (map[string]interface{}, error) {
+		result, err := include.LoadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return result.Data, nil
+	}
+
+	result, err := loader.LoadFile(path)
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+
+	if result.Profile == "" {
+		fmt.Fprintln(os.Stderr, "профиль не выбран, выводится базовая конфигурация")
+	} else if len(result.Overlays) == 0 {
+		fmt.Fprintf(os.Stderr, "предупреждение: для профиля %s нет ни блока, ни файлов\n", result.Profile)
+	}
+
+	if c.Bool("interpolate") {
+		if err := interpolate.New().Apply(result.Data); err != nil {
+			return cli.NewExitError(err.Error(), 1)
+		}
+	}
+
+	if keyFile := c.String("key-file"); keyFile != "" {
+		cipher, err := secrets.LoadKeyFile(keyFile)
+		if err != nil {
+			return cli.NewExitError(err.Error(), 1)
+		}
+		if err := cipher.Apply(result.Data); err != nil {
+			return cli.NewExitError(err.Error(), 1)
+		}
+	}
+
+	if c.Bool("provenance") {
+		fmt.Printf("# профиль: %s\n", result.Profile)
+		for _, line := range result.Provenance() {
+			fmt.Println(line)
+		}
+		return nil
+	}
+
+	format, err := outputFormat(c.String("to"), path)
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+
+	data := result.Data
+	if !c.Bool("show-secrets") {
+		data = secrets.Redact(data)
+	}
+
+	out, err := generators.Generate(format, data)
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+	_, err = os.Stdout.Write(out)
+	return err
+}
+
+// outputFormat возвращает формат из флага или формат входного файла
+func outputFormat(flag, path string) (types.ConfigFormat, error) {
+	if flag != "" {
+		format := parsers.ParseFormat(flag)
+		_, err := generators.Get(format)
+		return format, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return parsers.DetectFormat(path, data)
+}
@@ -0,0 +1,125 @@
+package main
+
+// Шифрование значений конфигураций в формат ENC[...]: main.go
+// go run ./cmd/wrk-configs/cmd/config-secret keygen > config.key
+// printf '%s' 's3cr3t' | go run ./cmd/wrk-configs/cmd/config-secret -k config.key encrypt
+// CONFIG_KEY_FILE=config.key go run ./cmd/wrk-configs/cmd/config-secret decrypt 'ENC[AES256_GCM,...]'
+// Значение читается из stdin (без завершающего перевода строки). Открытый текст
+// в аргументе encrypt принимается только с --arg: он попадает в историю shell и ps
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/secrets"
+	"gopkg.in/urfave/cli.v1"
+)
+
+func main() {
+	app := cli.NewApp()
+	app.Name = "config-secret"
+	app.Usage = "Generate keys and encrypt or decrypt ENC[...] configuration values"
+	app.Flags = []cli.Flag{
+		cli.StringFlag{
+			Name:   "key-file, k",
+			EnvVar: secrets.KeyFileEnv,
+			Usage:  "Key file (32 bytes raw, hex or base64)",
+		},
+	}
+	app.Commands = []cli.Command{
+		{
+			Name:   "keygen",
+			Usage:  "Print a new random key in hex",
+			Action: keygen,
+		},
+		{
+			Name:      "encrypt",
+			Usage:     "Encrypt a value read from stdin",
+			ArgsUsage: "[value]",
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "arg",
+					Usage: "Take the plaintext from the argument (WARNING: it is kept in shell history and visible in ps)",
+				},
+			},
+			Action: encrypt,
+		},
+		{
+			Name:      "decrypt",
+			Usage:     "Decrypt an ENC[...] value",
+			ArgsUsage: "[value]",
+			Action:    decrypt,
+		},
+	}
+
+	if err := app.Run(os.Args); err != nil {
+		os.Exit(1)
+	}
+}
+
+// keygen выводит новый ключ
+func keygen(c *cli.Context) error {
+	key, err := secrets.GenerateKey()
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+	fmt.Println(key)
+	return nil
+}
+
+// encrypt шифрует значение из stdin, а с --arg - из аргумента
+func encrypt(c *cli.Context) error {
+	return transform(c, c.Bool("arg"), func(cipher *secrets.Cipher, value string) (string, error) {
+		return cipher.Encrypt(value)
+	})
+}
+
+// decrypt расшифровывает значение из аргумента или stdin
+func decrypt(c *cli.Context) error {
+	return transform(c, true, func(cipher *secrets.Cipher, value string) (string, error) {
+		return cipher.Decrypt(strings.TrimSpace(value))
+	})
+}
+
+// transform загружает ключ, читает значение и выводит результат fn.
+// allowArg разрешает брать значение из аргумента
+func transform(c *cli.Context, allowArg bool, fn func(*secrets.Cipher, string) (string, error)) error {
+	keyFile := c.GlobalString("key-file")
+	if keyFile == "" {
+		return cli.NewExitError("укажите файл ключа: --key-file или "+secrets.KeyFileEnv, 2)
+	}
+	cipher, err := secrets.LoadKeyFile(keyFile)
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+
+	value, err := readValue(c, allowArg)
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+
+	result, err := fn(cipher, value)
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+	fmt.Println(result)
+	return nil
+}
+
+// readValue возвращает первый аргумент (если allowArg) или содержимое stdin
+func readValue(c *cli.Context, allowArg bool) (string, error) {
+	if c.NArg() > 0 {
+		if !allowArg {
+			return "", errors.New("открытый текст в аргументе попадет в историю shell: передайте его через stdin или укажите --arg")
+		}
+		return c.Args().First(), nil
+	}
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
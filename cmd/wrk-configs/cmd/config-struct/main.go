@@ -0,0 +1,97 @@
+package main
+
+// Генерация Go-структур и функции Load по образцам конфигураций: main.go
+// go run ./cmd/wrk-configs/cmd/config-struct cmd/wrk-configs/configs/examples/app.yml
+// go run ./cmd/wrk-configs/cmd/config-struct -p appconfig -o config_gen.go --validate --defaults app.yml app.prod.toml
+// В исходнике пакета для go generate:
+// //go:generate go run github.com/KornilovLN/go-na-practike/cmd/wrk-configs/cmd/config-struct -o config_gen.go app.yml
+// Несколько образцов сливаются в один тип; имя пакета по умолчанию берется
+// из $GOPACKAGE, который задает go generate
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/generators"
+	"gopkg.in/urfave/cli.v1"
+)
+
+func main() {
+	app := cli.NewApp()
+	app.Name = "config-struct"
+	app.Usage = "Generate a gofmt'd Go file with structs and a typed Load function from sample configurations"
+	app.ArgsUsage = "<sample file>..."
+	app.Flags = []cli.Flag{
+		cli.StringFlag{
+			Name:   "package, p",
+			Value:  "config",
+			EnvVar: "GOPACKAGE",
+			Usage:  "Package name of the generated file",
+		},
+		cli.StringFlag{
+			Name:  "type, t",
+			Value: "Config",
+			Usage: "Name of the root struct returned by Load",
+		},
+		cli.StringFlag{
+			Name:  "output, o",
+			Usage: "Output .go file; stdout if not set",
+		},
+		cli.BoolFlag{
+			Name:  "validate",
+			Usage: "Generate Validate methods that reject empty strings that are set in every sample",
+		},
+		cli.BoolFlag{
+			Name:  "defaults",
+			Usage: "Generate Default methods that fill unset fields with sample values",
+		},
+	}
+	app.Action = generate
+
+	if err := app.Run(os.Args); err != nil {
+		os.Exit(1)
+	}
+}
+
+// generate анализирует образцы и записывает сгенерированный файл
+func generate(c *cli.Context) error {
+	if c.NArg() == 0 {
+		return cli.NewExitError("укажите хотя бы один файл-образец", 2)
+	}
+
+	root := c.String("type")
+	generator := generators.NewStructGenerator()
+	sources := make([]string, 0, c.NArg())
+	for _, path := range c.Args() {
+		if err := generator.GenerateFromFile(path, root); err != nil {
+			return cli.NewExitError(err.Error(), 1)
+		}
+		sources = append(sources, filepath.Base(path))
+	}
+
+	code, err := generator.GenerateGoFile(generators.GoFileOptions{
+		Package:  c.String("package"),
+		Root:     root,
+		Source:   strings.Join(sources, ", "),
+		Validate: c.Bool("validate"),
+		Defaults: c.Bool("defaults"),
+	})
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+	for _, warning := range generator.Warnings() {
+		fmt.Fprintf(os.Stderr, "предупреждение: %s\n", warning)
+	}
+
+	output := c.String("output")
+	if output == "" {
+		fmt.Print(string(code))
+		return nil
+	}
+	if err := os.WriteFile(output, code, 0644); err != nil {
+		return cli.NewExitError(fmt.Sprintf("не удалось записать %s: %v", output, err), 1)
+	}
+	return nil
+}
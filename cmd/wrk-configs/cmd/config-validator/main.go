@@ -0,0 +1,106 @@
+package main
+
+// Проверка конфигурационных файлов по JSON Schema: main.go
+// go run ./cmd/wrk-configs/cmd/config-validator cmd/wrk-configs/configs/examples/app.yml
+// go run ./cmd/wrk-configs/cmd/config-validator --schema cmd/wrk-configs/configs/schemas/app.schema.json app.ini
+// Без --schema схема ищется по имени файла (app.yml -> app.schema.json) в --schema-dir,
+// а если он не задан - рядом с файлом и в ../schemas относительно файла
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/schema"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// schemaDirs директории поиска схемы относительно конфигурационного файла,
+// если --schema-dir не задан (раскладка configs/examples и configs/schemas)
+var schemaDirs = []string{".", "../schemas"}
+
+func main() {
+	app := cli.NewApp()
+	app.Name = "config-validator"
+	app.Usage = "Validate JSON, YAML, INI and TOML configuration files against a JSON Schema"
+	app.ArgsUsage = "<config file> [config file...]"
+	app.Flags = []cli.Flag{
+		cli.StringFlag{
+			Name:  "schema, s",
+			Usage: "Schema file; looked up in --schema-dir by config file name if omitted",
+		},
+		cli.StringFlag{
+			Name:  "schema-dir, d",
+			Usage: "Directory with <name>.schema.json files; next to the config file and in its ../schemas if omitted",
+		},
+	}
+	app.Action = validate
+
+	if err := app.Run(os.Args); err != nil {
+		os.Exit(1)
+	}
+}
+
+// validate проверяет все файлы из аргументов и выводит каждое нарушение
+func validate(c *cli.Context) error {
+	if c.NArg() == 0 {
+		return cli.NewExitError("укажите хотя бы один конфигурационный файл", 2)
+	}
+
+	failed := 0
+	for _, path := range c.Args() {
+		schemaPath := c.String("schema")
+		if schemaPath == "" {
+			schemaPath = schemaFor(c.String("schema-dir"), path)
+		}
+
+		s, err := schema.Load(schemaPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+			failed++
+			continue
+		}
+
+		violations, err := s.ValidateFile(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+			failed++
+			continue
+		}
+
+		if len(violations) == 0 {
+			fmt.Printf("%s: OK (%s)\n", path, schemaPath)
+			continue
+		}
+
+		failed++
+		for _, v := range violations {
+			fmt.Printf("%s:%s\n", path, v)
+		}
+	}
+
+	if failed > 0 {
+		return cli.NewExitError(fmt.Sprintf("не прошли проверку: %d из %d", failed, c.NArg()), 1)
+	}
+	return nil
+}
+
+// schemaFor возвращает путь к схеме по имени конфигурационного файла.
+// Без dir возвращается первая найденная схема из schemaDirs или,
+// если схемы нет, путь в первой из них (для сообщения об ошибке)
+func schemaFor(dir, path string) string {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)) + ".schema.json"
+	if dir != "" {
+		return filepath.Join(dir, name)
+	}
+
+	candidates := make([]string, len(schemaDirs))
+	for i, d := range schemaDirs {
+		candidates[i] = filepath.Join(filepath.Dir(path), d, name)
+		if _, err := os.Stat(candidates[i]); err == nil {
+			return candidates[i]
+		}
+	}
+	return candidates[0]
+}
@@ -21,8 +21,8 @@ func main() {
 	}
 
 	fmt.Printf("Конфигурация загружена:\n")
-	fmt.Printf("  Database: %s:%d (user: %s)\n",
-		config.Database.Host, config.Database.Port, config.Database.Username)
+	fmt.Printf("  Database: %s:%d (user: %s, password: %s)\n",
+		config.Database.Host, config.Database.Port, config.Database.Username, config.Database.Password)
 	fmt.Printf("  Server: %s:%d\n",
 		config.Server.Host, config.Server.Port)
 	fmt.Printf("  Debug: %v\n", config.Debug)
@@ -0,0 +1,51 @@
+package main
+
+// Наложение переменных окружения на конфигурацию (12-factor): main.go
+// APP_DATABASE_HOST=db.local APP_SERVER_PORT=9090 APP_DEBUG=false \
+//   go run ./cmd/wrk-configs/examples/08-env-overlay cmd/wrk-configs/configs/examples/app.yml
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/env"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/types"
+)
+
+func main() {
+	filePath := "cmd/wrk-configs/configs/examples/app.json"
+	if len(os.Args) > 1 {
+		filePath = os.Args[1]
+	}
+
+	overlay := env.NewOverlay("APP")
+
+	var config types.CommonConfig
+	report, err := overlay.LoadFile(filePath, &config)
+	if err != nil {
+		log.Fatal("Ошибка загрузки:", err)
+	}
+
+	fmt.Printf("Конфигурация %s с учетом окружения:\n", filePath)
+	fmt.Printf("  Database: %s:%d (user: %s)\n",
+		config.Database.Host, config.Database.Port, config.Database.Username)
+	fmt.Printf("  Server: %s:%d\n", config.Server.Host, config.Server.Port)
+	fmt.Printf("  Debug: %v\n", config.Debug)
+	fmt.Printf("  Logging: %s -> %s\n", config.Logging.Level, config.Logging.File)
+
+	fmt.Println("\nПереопределено из окружения:")
+	for _, o := range report.Overrides {
+		fmt.Printf("  %s <- %s=%s\n", o.Key, o.Variable, o.Value)
+	}
+	for _, name := range report.Unmatched {
+		fmt.Printf("  %s: ключ не найден\n", name)
+	}
+
+	// Тот же механизм работает и для динамической конфигурации
+	dynamic := map[string]interface{}{}
+	if _, err := overlay.LoadFile("cmd/wrk-configs/configs/examples/test_config.json", &dynamic); err != nil {
+		log.Fatal("Ошибка загрузки:", err)
+	}
+	fmt.Printf("\nserver.limits (test_config.json): %v\n", dynamic["server"].(map[string]interface{})["limits"])
+}
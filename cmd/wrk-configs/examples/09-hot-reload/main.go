@@ -0,0 +1,53 @@
+package main
+
+// Перезагрузка конфигурации при изменении файла: main.go
+// go run ./cmd/wrk-configs/examples/09-hot-reload cmd/wrk-configs/configs/examples/app.yml
+// и в другом терминале измените порт сервера в app.yml
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/types"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/watcher"
+)
+
+func main() {
+	filePath := "cmd/wrk-configs/configs/examples/app.json"
+	if len(os.Args) > 1 {
+		filePath = os.Args[1]
+	}
+
+	w, err := watcher.NewFile(filePath, func() interface{} { return new(types.CommonConfig) })
+	if err != nil {
+		log.Fatal("Ошибка загрузки:", err)
+	}
+
+	config := w.Current().(*types.CommonConfig)
+	fmt.Printf("Сервер: %s:%d, наблюдаем за %s (Ctrl+C для выхода)\n",
+		config.Server.Host, config.Server.Port, filePath)
+
+	events := w.Subscribe() // Канал, в который приходят новые версии конфигурации
+	w.Start()
+	defer w.Stop()
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+
+	for {
+		select {
+		case event := <-events:
+			if event.Err != nil {
+				fmt.Printf("Изменение отклонено, используется прежняя конфигурация: %v\n", event.Err)
+				continue
+			}
+			config := event.Value.(*types.CommonConfig)
+			fmt.Printf("Конфигурация обновлена: сервер %s:%d\n", config.Server.Host, config.Server.Port)
+		case <-interrupt:
+			fmt.Println("Завершение")
+			return
+		}
+	}
+}
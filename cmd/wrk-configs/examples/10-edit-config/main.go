@@ -0,0 +1,67 @@
+package main
+
+// Программное редактирование конфигурации: main.go
+//   go run ./cmd/wrk-configs/examples/10-edit-config app.yml \
+//     server.port=9090 server.tls.cert=cert.pem 'server.middlewares[]=gzip' -database.password
+//
+// key=value записывает значение, key[]=value добавляет в массив, -key удаляет ключ.
+// Значение разбирается как JSON (9090, true, ["a","b"]), иначе считается строкой.
+// Файл сохраняется в исходном формате; для YAML, INI и TOML комментарии
+// и порядок ключей сохраняются, если ключи не удалялись.
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/reader"
+)
+
+func main() {
+	if len(os.Args) < 3 {
+		fmt.Println("Использование: go run ./cmd/wrk-configs/examples/10-edit-config <файл> key=value|key[]=value|-key ...")
+		return
+	}
+
+	filePath := os.Args[1]
+
+	cr := reader.NewConfigReader()
+	if err := cr.ReadFile(filePath); err != nil {
+		log.Fatal("Ошибка чтения:", err)
+	}
+
+	for _, arg := range os.Args[2:] {
+		if err := apply(cr, arg); err != nil {
+			log.Fatalf("%s: %v", arg, err)
+		}
+	}
+
+	if err := cr.Save(); err != nil {
+		log.Fatal("Ошибка сохранения:", err)
+	}
+	fmt.Printf("Файл %s (%s) обновлен\n", cr.FilePath, cr.Format)
+}
+
+// apply выполняет одну операцию редактирования
+func apply(cr *reader.ConfigReader, arg string) error {
+	if strings.HasPrefix(arg, "-") {
+		return cr.Delete(arg[1:])
+	}
+
+	key, raw, ok := strings.Cut(arg, "=")
+	if !ok {
+		return fmt.Errorf("ожидается key=value")
+	}
+
+	var value interface{} = raw
+	if err := json.Unmarshal([]byte(raw), &value); err != nil {
+		value = raw
+	}
+
+	if key, ok := strings.CutSuffix(key, "[]"); ok {
+		return cr.Append(key, value)
+	}
+	return cr.Set(key, value)
+}
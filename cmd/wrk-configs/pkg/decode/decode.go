@@ -0,0 +1,471 @@
+package decode
+
+// decode.go
+
+import (
+	"encoding"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/keypath"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/utils"
+)
+
+// Decoder раскладывает динамическое значение (map[string]interface{},
+// []interface{}, скаляры из парсеров) в структуры, срезы, карты и скаляры Go.
+// Поля структур сопоставляются ключам по тегам json, yaml, toml и ini,
+// а затем по имени поля без учета регистра
+type Decoder struct {
+	// Weak разрешает приведение типов: "5432" -> int, "true" -> bool,
+	// 8080 -> string, "a, b" -> []string. Нужно для INI и окружения
+	Weak bool
+	// Strict превращает неиспользованные ключи в ошибки
+	Strict bool
+	// Prefix добавляется к путям в ошибках и отчете (например, "database")
+	Prefix string
+}
+
+// Report результат декодирования
+type Report struct {
+	// Unused ключи входных данных, которым не нашлось поля
+	Unused []string
+}
+
+// FieldError ошибка декодирования одного значения
+type FieldError struct {
+	Path    string
+	Message string
+}
+
+func (e *FieldError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// Errors все ошибки декодирования
+type Errors []*FieldError
+
+func (e Errors) Error() string {
+	lines := make([]string, len(e))
+	for i, err := range e {
+		lines[i] = "  " + err.Error()
+	}
+	return fmt.Sprintf("не удалось декодировать конфигурацию (%d):\n%s", len(e), strings.Join(lines, "\n"))
+}
+
+// NewDecoder создает декодер с приведением типов и без строгого режима
+func NewDecoder() *Decoder {
+	return &Decoder{Weak: true}
+}
+
+// Decode декодирует input в out декодером по умолчанию
+func Decode(input, out interface{}) error {
+	_, err := NewDecoder().Decode(input, out)
+	return err
+}
+
+// Decode декодирует input в out (указатель). Декодирование продолжается
+// после ошибок отдельных полей, все они возвращаются в Errors
+func (d *Decoder) Decode(input, out interface{}) (*Report, error) {
+	rv := reflect.ValueOf(out)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return nil, fmt.Errorf("decode: ожидается ненулевой указатель, получен %T", out)
+	}
+
+	state := &state{decoder: d, report: &Report{}}
+	state.decode(d.Prefix, input, rv.Elem())
+
+	sort.Strings(state.report.Unused)
+	if d.Strict {
+		for _, key := range state.report.Unused {
+			state.fail(key, "неизвестный ключ")
+		}
+	}
+
+	if len(state.errs) > 0 {
+		sort.SliceStable(state.errs, func(i, j int) bool { return state.errs[i].Path < state.errs[j].Path })
+		return state.report, state.errs
+	}
+	return state.report, nil
+}
+
+// state состояние одного вызова Decode
+type state struct {
+	decoder *Decoder
+	report  *Report
+	errs    Errors
+}
+
+func (s *state) fail(path, format string, args ...interface{}) {
+	s.errs = append(s.errs, &FieldError{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	timeType            = reflect.TypeOf(time.Time{})
+	urlType             = reflect.TypeOf(url.URL{})
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// decode записывает input в v
+func (s *state) decode(path string, input interface{}, v reflect.Value) {
+	if input == nil {
+		// null оставляет значение по умолчанию
+		return
+	}
+
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		s.decode(path, input, v.Elem())
+		return
+	}
+
+	if v.Kind() == reflect.Interface && v.NumMethod() == 0 {
+		v.Set(reflect.ValueOf(input))
+		return
+	}
+
+	switch v.Type() {
+	case durationType:
+		s.convert(path, input, v, "длительность", func() (interface{}, error) {
+			if !s.decoder.Weak {
+				if _, ok := input.(string); !ok {
+					return nil, fmt.Errorf("ожидается строка длительности")
+				}
+			}
+			return utils.ToDuration(input)
+		})
+		return
+	case timeType:
+		s.convert(path, input, v, "время", func() (interface{}, error) { return utils.ToTime(input) })
+		return
+	case urlType:
+		s.convert(path, input, v, "URL", func() (interface{}, error) {
+			u, err := utils.ToURL(input)
+			if err != nil {
+				return nil, err
+			}
+			return *u, nil
+		})
+		return
+	}
+
+	if str, ok := input.(string); ok && reflect.PointerTo(v.Type()).Implements(textUnmarshalerType) {
+		if err := v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(str)); err != nil {
+			s.fail(path, "%v", err)
+		}
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		if str, ok := input.(string); ok {
+			v.SetString(str)
+		} else if text, ok := s.scalarText(input); ok {
+			v.SetString(text)
+		} else {
+			s.mismatch(path, input, v)
+		}
+
+	case reflect.Bool:
+		if b, ok := input.(bool); ok {
+			v.SetBool(b)
+		} else if s.decoder.Weak {
+			s.convert(path, input, v, "bool", func() (interface{}, error) { return utils.ToBool(input) })
+		} else {
+			s.mismatch(path, input, v)
+		}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if !s.numeric(input) {
+			s.mismatch(path, input, v)
+			return
+		}
+		n, err := utils.ToInt64(input)
+		if err != nil {
+			s.fail(path, "%v", err)
+			return
+		}
+		if v.OverflowInt(n) {
+			s.fail(path, "число %d не помещается в %s", n, v.Type())
+			return
+		}
+		v.SetInt(n)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if !s.numeric(input) {
+			s.mismatch(path, input, v)
+			return
+		}
+		n, err := utils.ToInt64(input)
+		if err != nil {
+			s.fail(path, "%v", err)
+			return
+		}
+		if n < 0 || v.OverflowUint(uint64(n)) {
+			s.fail(path, "число %d не помещается в %s", n, v.Type())
+			return
+		}
+		v.SetUint(uint64(n))
+
+	case reflect.Float32, reflect.Float64:
+		if !s.numeric(input) {
+			s.mismatch(path, input, v)
+			return
+		}
+		f, err := utils.ToFloat64(input)
+		if err != nil {
+			s.fail(path, "%v", err)
+			return
+		}
+		if v.OverflowFloat(f) {
+			s.fail(path, "число %g не помещается в %s", f, v.Type())
+			return
+		}
+		v.SetFloat(f)
+
+	case reflect.Slice:
+		items, ok := s.items(input)
+		if !ok {
+			s.mismatch(path, input, v)
+			return
+		}
+		slice := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for i, item := range items {
+			s.decode(keypath.Index(path, i), item, slice.Index(i))
+		}
+		v.Set(slice)
+
+	case reflect.Array:
+		items, ok := s.items(input)
+		if !ok {
+			s.mismatch(path, input, v)
+			return
+		}
+		if len(items) > v.Len() {
+			s.fail(path, "массив из %d элементов не помещается в %s", len(items), v.Type())
+			return
+		}
+		for i, item := range items {
+			s.decode(keypath.Index(path, i), item, v.Index(i))
+		}
+
+	case reflect.Map:
+		obj, ok := input.(map[string]interface{})
+		if !ok || v.Type().Key().Kind() != reflect.String {
+			s.mismatch(path, input, v)
+			return
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMapWithSize(v.Type(), len(obj)))
+		}
+		for _, key := range utils.SortedKeys(obj) {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			s.decode(keypath.Join(path, key), obj[key], elem)
+			v.SetMapIndex(reflect.ValueOf(key).Convert(v.Type().Key()), elem)
+		}
+
+	case reflect.Struct:
+		obj, ok := input.(map[string]interface{})
+		if !ok {
+			s.mismatch(path, input, v)
+			return
+		}
+		s.decodeStruct(path, obj, v)
+
+	default:
+		s.fail(path, "неподдерживаемый тип %s", v.Type())
+	}
+}
+
+// decodeStruct сопоставляет ключи объекта полям структуры
+func (s *state) decodeStruct(path string, obj map[string]interface{}, v reflect.Value) {
+	used := make(map[string]bool, len(obj))
+
+	for _, f := range structFields(v.Type()) {
+		key, ok := matchKey(obj, f.names)
+		if !ok {
+			continue
+		}
+		used[key] = true
+		s.decode(keypath.Join(path, key), obj[key], fieldByIndex(v, f.index))
+	}
+
+	for _, key := range utils.SortedKeys(obj) {
+		if !used[key] {
+			s.report.Unused = append(s.report.Unused, keypath.Join(path, key))
+		}
+	}
+}
+
+// convert выполняет преобразование и записывает результат в v
+func (s *state) convert(path string, input interface{}, v reflect.Value, target string, fn func() (interface{}, error)) {
+	result, err := fn()
+	if err != nil {
+		s.fail(path, "не удалось преобразовать %#v в %s: %v", input, target, err)
+		return
+	}
+	v.Set(reflect.ValueOf(result).Convert(v.Type()))
+}
+
+// mismatch сообщает о несовпадении типов
+func (s *state) mismatch(path string, input interface{}, v reflect.Value) {
+	s.fail(path, "значение %#v (%T) нельзя записать в %s", input, input, v.Type())
+}
+
+// numeric проверяет, что значение - число, или строка в режиме Weak
+func (s *state) numeric(input interface{}) bool {
+	switch input.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return true
+	case string:
+		return s.decoder.Weak
+	}
+	return false
+}
+
+// scalarText в режиме Weak форматирует число или bool как строку
+func (s *state) scalarText(input interface{}) (string, bool) {
+	if !s.decoder.Weak {
+		return "", false
+	}
+	switch v := input.(type) {
+	case bool:
+		return strconv.FormatBool(v), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32), true
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprint(v), true
+	}
+	return "", false
+}
+
+// items возвращает элементы массива; в режиме Weak строка разбивается по запятым
+func (s *state) items(input interface{}) ([]interface{}, bool) {
+	switch v := input.(type) {
+	case []interface{}:
+		return v, true
+	case string:
+		if !s.decoder.Weak {
+			return nil, false
+		}
+		parts := utils.SplitList(v)
+		items := make([]interface{}, len(parts))
+		for i, part := range parts {
+			items[i] = part
+		}
+		return items, true
+	}
+	return nil, false
+}
+
+// field поле структуры (с учетом встроенных структур) и его возможные ключи
+type field struct {
+	index []int
+	names []string
+}
+
+// structFields возвращает поля структуры; поля встроенных структур без имени
+// ключа поднимаются на уровень структуры, как в encoding/json
+func structFields(t reflect.Type) []field {
+	var fields []field
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		names, inline, skip := fieldNames(sf)
+		if skip {
+			continue
+		}
+
+		ft := sf.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if inline && ft.Kind() == reflect.Struct {
+			for _, inner := range structFields(ft) {
+				inner.index = append([]int{i}, inner.index...)
+				fields = append(fields, inner)
+			}
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+
+		fields = append(fields, field{index: []int{i}, names: names})
+	}
+
+	return fields
+}
+
+// fieldNames собирает имена ключа из тегов json, yaml, toml и ini и имя поля.
+// inline - встроенная структура без имени или с опцией inline/squash
+func fieldNames(sf reflect.StructField) (names []string, inline, skip bool) {
+	tagged := false
+	for _, tagName := range []string{"json", "yaml", "toml", "ini", "mapstructure"} {
+		tag, ok := sf.Tag.Lookup(tagName)
+		if !ok {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "-" && opts == "" {
+			return nil, false, true
+		}
+		if strings.Contains(","+opts+",", ",inline,") || strings.Contains(","+opts+",", ",squash,") {
+			inline = true
+		}
+		if name != "" {
+			names = append(names, name)
+			tagged = true
+		}
+	}
+
+	if sf.Anonymous && !tagged {
+		inline = true
+	}
+	names = append(names, sf.Name)
+	return names, inline, false
+}
+
+// matchKey ищет ключ объекта для поля: сначала точное совпадение
+// с одним из имен, затем без учета регистра
+func matchKey(obj map[string]interface{}, names []string) (string, bool) {
+	for _, name := range names {
+		if _, ok := obj[name]; ok {
+			return name, true
+		}
+	}
+	for _, key := range utils.SortedKeys(obj) {
+		for _, name := range names {
+			if strings.EqualFold(key, name) {
+				return key, true
+			}
+		}
+	}
+	return "", false
+}
+
+// fieldByIndex как reflect.Value.FieldByIndex, но создает nil-указатели на встроенные структуры
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
@@ -0,0 +1,416 @@
+package diff
+
+// diff.go
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/keypath"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/parsers"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/patch"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/secrets"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/types"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/utils"
+)
+
+// Kind вид изменения
+type Kind string
+
+const (
+	Added   Kind = "added"
+	Removed Kind = "removed"
+	Changed Kind = "changed"
+)
+
+// Change одно различие между конфигурациями.
+// Path - путь keypath ("server.middlewares[1]"); Old не задан для Added,
+// New - для Removed. OldType и NewType - типы значений в терминах JSON
+// ("string", "number", "boolean", "object", "array", "null")
+type Change struct {
+	Kind    Kind
+	Path    string
+	Old     interface{}
+	New     interface{}
+	OldType string
+	NewType string
+
+	// pointer JSON Pointer, собранный из исходных ключей при сравнении
+	pointer string
+}
+
+// TypeChanged сообщает, что у измененного значения сменился тип
+func (c Change) TypeChanged() bool {
+	return c.Kind == Changed && c.OldType != c.NewType
+}
+
+// String возвращает строку вида "+ path: new", "- path: old", "~ path: old -> new"
+func (c Change) String() string {
+	switch c.Kind {
+	case Added:
+		return fmt.Sprintf("+ %s: %s", c.Path, formatValue(c.New))
+	case Removed:
+		return fmt.Sprintf("- %s: %s", c.Path, formatValue(c.Old))
+	}
+	if c.TypeChanged() {
+		return fmt.Sprintf("~ %s: %s (%s) -> %s (%s)", c.Path, formatValue(c.Old), c.OldType, formatValue(c.New), c.NewType)
+	}
+	return fmt.Sprintf("~ %s: %s -> %s", c.Path, formatValue(c.Old), formatValue(c.New))
+}
+
+// MarshalJSON записывает только значимые для вида изменения поля
+func (c Change) MarshalJSON() ([]byte, error) {
+	m := map[string]interface{}{"kind": c.Kind, "path": c.Path}
+	if c.Kind != Added {
+		m["old"] = c.Old
+		m["old_type"] = c.OldType
+	}
+	if c.Kind != Removed {
+		m["new"] = c.New
+		m["new_type"] = c.NewType
+	}
+	return json.Marshal(m)
+}
+
+// Differ сравнивает динамические конфигурации
+type Differ struct {
+	// Loose сравнивает строку со значением другого типа по смыслу:
+	// "8080" равно 8080, "a, b" равно ["a", "b"]. Нужен для INI,
+	// где все значения - строки
+	Loose bool
+}
+
+// NewDiffer создает Differ со строгим сравнением типов
+func NewDiffer() *Differ {
+	return &Differ{}
+}
+
+// Compare сравнивает конфигурации строгим Differ
+func Compare(old, updated map[string]interface{}) []Change {
+	return NewDiffer().Compare(old, updated)
+}
+
+// Files загружает два файла любых форматов и сравнивает их.
+// Если один из файлов - INI, сравнение нестрогое (Differ.Loose)
+func Files(oldPath, newPath string) ([]Change, error) {
+	old, oldFormat, err := parsers.LoadDynamicFile(oldPath)
+	if err != nil {
+		return nil, err
+	}
+	updated, newFormat, err := parsers.LoadDynamicFile(newPath)
+	if err != nil {
+		return nil, err
+	}
+
+	d := NewDiffer()
+	d.Loose = oldFormat == types.FormatINI || newFormat == types.FormatINI
+	return d.Compare(old, updated), nil
+}
+
+// Compare возвращает различия по путям в порядке ключей.
+// Числа сравниваются по значению независимо от типа (int из YAML и float64 из JSON).
+// Удаленные хвостовые элементы массива перечисляются с конца,
+// чтобы построенный по ним JSON Patch применялся последовательно
+func (d *Differ) Compare(old, updated map[string]interface{}) []Change {
+	changes := []Change{}
+	d.compare("", "", old, updated, &changes)
+	return changes
+}
+
+// compare сравнивает значения по пути path; pointer - тот же путь в виде
+// JSON Pointer, собранный из исходных ключей, а не из записи path
+func (d *Differ) compare(path, pointer string, old, updated interface{}, changes *[]Change) {
+	if d.Loose {
+		old, updated = d.splitLists(old, updated)
+	}
+
+	switch o := old.(type) {
+	case map[string]interface{}:
+		u, ok := updated.(map[string]interface{})
+		if !ok {
+			break
+		}
+		for _, key := range unionKeys(o, u) {
+			keyPath := keypath.Join(path, key)
+			keyPointer := patch.AppendPointer(pointer, key)
+			oldValue, inOld := o[key]
+			newValue, inNew := u[key]
+			switch {
+			case !inNew:
+				*changes = append(*changes, newChange(Removed, keyPath, keyPointer, oldValue, nil))
+			case !inOld:
+				*changes = append(*changes, newChange(Added, keyPath, keyPointer, nil, newValue))
+			default:
+				d.compare(keyPath, keyPointer, oldValue, newValue, changes)
+			}
+		}
+		return
+
+	case []interface{}:
+		u, ok := updated.([]interface{})
+		if !ok {
+			break
+		}
+		common := len(o)
+		if len(u) < common {
+			common = len(u)
+		}
+		for i := 0; i < common; i++ {
+			d.compare(keypath.Index(path, i), indexPointer(pointer, i), o[i], u[i], changes)
+		}
+		for i := common; i < len(u); i++ {
+			*changes = append(*changes, newChange(Added, keypath.Index(path, i), indexPointer(pointer, i), nil, u[i]))
+		}
+		for i := len(o) - 1; i >= common; i-- {
+			*changes = append(*changes, newChange(Removed, keypath.Index(path, i), indexPointer(pointer, i), o[i], nil))
+		}
+		return
+	}
+
+	if !d.equal(old, updated) {
+		*changes = append(*changes, newChange(Changed, path, pointer, old, updated))
+	}
+}
+
+// splitLists в нестрогом режиме превращает строку-список INI в массив,
+// если с другой стороны массив
+func (d *Differ) splitLists(old, updated interface{}) (interface{}, interface{}) {
+	split := func(s string) []interface{} {
+		items := utils.SplitList(s)
+		result := make([]interface{}, len(items))
+		for i, item := range items {
+			result[i] = item
+		}
+		return result
+	}
+
+	if s, ok := old.(string); ok {
+		if _, isArr := updated.([]interface{}); isArr {
+			return split(s), updated
+		}
+	}
+	if s, ok := updated.(string); ok {
+		if _, isArr := old.([]interface{}); isArr {
+			return old, split(s)
+		}
+	}
+	return old, updated
+}
+
+// equal сравнивает скалярные значения
+func (d *Differ) equal(a, b interface{}) bool {
+	if reflect.DeepEqual(a, b) {
+		return true
+	}
+	if x, ok := toFloat(a); ok {
+		if y, ok := toFloat(b); ok {
+			return x == y
+		}
+	}
+	if d.Loose {
+		if s, ok := a.(string); ok && looseEqual(s, b) {
+			return true
+		}
+		if s, ok := b.(string); ok && looseEqual(s, a) {
+			return true
+		}
+	}
+	// Даты TOML и строки RFC 3339 из JSON
+	return canonical(a) == canonical(b)
+}
+
+// looseEqual сравнивает строку со значением другого типа, приводя строку к его типу
+func looseEqual(s string, other interface{}) bool {
+	switch other.(type) {
+	case map[string]interface{}, []interface{}, nil:
+		return false
+	}
+	converted, err := utils.ParseLike(s, other)
+	if err != nil {
+		return false
+	}
+	if x, ok := toFloat(converted); ok {
+		if y, ok := toFloat(other); ok {
+			return x == y
+		}
+	}
+	return reflect.DeepEqual(converted, other)
+}
+
+// Patch строит JSON Patch (RFC 6902), превращающий старую конфигурацию в новую.
+// Указатели берутся из ключей, найденных Compare, для изменений, собранных
+// вручную, - из Path. Изменения после Redact отклоняются: патч должен
+// содержать настоящие значения
+func Patch(changes []Change) (patch.Patch, error) {
+	ops := make(patch.Patch, 0, len(changes))
+	for _, c := range changes {
+		pointer := c.pointer
+		if pointer == "" {
+			var err error
+			if pointer, err = patch.Pointer(c.Path); err != nil {
+				return nil, err
+			}
+		}
+		// Патч из скрытых изменений записал бы Redacted вместо значения
+		if c.Kind != Removed && containsRedacted(c.New) {
+			return nil, fmt.Errorf("%s: значение скрыто, патч строится только по исходным изменениям", c.Path)
+		}
+
+		switch c.Kind {
+		case Added:
+			ops = append(ops, patch.Operation{Op: patch.OpAdd, Path: pointer, Value: c.New})
+		case Removed:
+			ops = append(ops, patch.Operation{Op: patch.OpRemove, Path: pointer})
+		case Changed:
+			ops = append(ops, patch.Operation{Op: patch.OpReplace, Path: pointer, Value: c.New})
+		}
+	}
+	return ops, nil
+}
+
+// Redact возвращает копию изменений, в которой значения по чувствительным
+// путям и чувствительные ключи внутри объектов заменены на secrets.Redacted
+func Redact(changes []Change) []Change {
+	result := make([]Change, len(changes))
+	for i, c := range changes {
+		if c.Kind != Added {
+			c.Old = redactValue(c.Path, c.Old)
+		}
+		if c.Kind != Removed {
+			c.New = redactValue(c.Path, c.New)
+		}
+		result[i] = c
+	}
+	return result
+}
+
+// HasSecrets сообщает, что Redact скрыл бы хотя бы одно значение
+func HasSecrets(changes []Change) bool {
+	return !reflect.DeepEqual(Redact(changes), changes)
+}
+
+// containsRedacted ищет secrets.Redacted в значении, включая вложенные
+func containsRedacted(value interface{}) bool {
+	switch v := value.(type) {
+	case string:
+		return v == secrets.Redacted
+	case map[string]interface{}:
+		for _, item := range v {
+			if containsRedacted(item) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if containsRedacted(item) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func redactValue(path string, value interface{}) interface{} {
+	if secrets.IsSensitive(path) {
+		return secrets.Redacted
+	}
+	return secrets.Redact(map[string]interface{}{"": value})[""]
+}
+
+// Text возвращает изменения построчно (Change.String)
+func Text(changes []Change) string {
+	var b strings.Builder
+	for _, c := range changes {
+		b.WriteString(c.String())
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+func newChange(kind Kind, path, pointer string, old, updated interface{}) Change {
+	c := Change{Kind: kind, Path: path, Old: old, New: updated, pointer: pointer}
+	if kind != Added {
+		c.OldType = TypeName(old)
+	}
+	if kind != Removed {
+		c.NewType = TypeName(updated)
+	}
+	return c
+}
+
+// indexPointer добавляет к JSON Pointer индекс массива
+func indexPointer(pointer string, i int) string {
+	return patch.AppendPointer(pointer, strconv.Itoa(i))
+}
+
+// TypeName возвращает тип значения в терминах JSON
+func TypeName(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case time.Time:
+		return "datetime"
+	}
+	if _, ok := toFloat(v); ok {
+		return "number"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// formatValue выводит значение компактным JSON
+func formatValue(v interface{}) string {
+	return canonical(v)
+}
+
+// canonical возвращает JSON-представление значения
+func canonical(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(data)
+}
+
+// toFloat приводит числа любых типов к float64
+func toFloat(v interface{}) (float64, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		f := rv.Float()
+		return f, !math.IsNaN(f)
+	}
+	return 0, false
+}
+
+// unionKeys возвращает отсортированное объединение ключей
+func unionKeys(a, b map[string]interface{}) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for key := range a {
+		keys = append(keys, key)
+	}
+	for key := range b {
+		if _, exists := a[key]; !exists {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
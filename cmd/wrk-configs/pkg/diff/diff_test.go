@@ -0,0 +1,171 @@
+package diff
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/patch"
+)
+
+func TestCompare(t *testing.T) {
+	old := map[string]interface{}{
+		"server": map[string]interface{}{"host": "localhost", "port": 8080.0, "debug": true},
+		"list":   []interface{}{1, 2, 3},
+		"gone":   "x",
+		"kind":   "1",
+	}
+	updated := map[string]interface{}{
+		"server": map[string]interface{}{"host": "example.com", "port": 8080, "tls": true},
+		"list":   []interface{}{1, 5},
+		"kind":   1,
+	}
+
+	want := []string{
+		"- gone: \"x\"",
+		"~ kind: \"1\" (string) -> 1 (number)",
+		"~ list[1]: 2 -> 5",
+		"- list[2]: 3",
+		"- server.debug: true",
+		"~ server.host: \"localhost\" -> \"example.com\"",
+		"+ server.tls: true",
+	}
+	var got []string
+	for _, c := range Compare(old, updated) {
+		got = append(got, c.String())
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("получено\n%q\nожидалось\n%q", got, want)
+	}
+
+	if changes := Compare(old, old); len(changes) != 0 {
+		t.Errorf("одинаковые конфигурации: %v", changes)
+	}
+}
+
+func TestCompareTrailingRemovals(t *testing.T) {
+	changes := Compare(
+		map[string]interface{}{"a": []interface{}{1, 2, 3, 4}},
+		map[string]interface{}{"a": []interface{}{1}},
+	)
+	var paths []string
+	for _, c := range changes {
+		paths = append(paths, c.Path)
+	}
+	if want := []string{"a[3]", "a[2]", "a[1]"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("пути = %q, ожидалось %q", paths, want)
+	}
+}
+
+func TestLoose(t *testing.T) {
+	old := map[string]interface{}{
+		"port": "8080", "debug": "true", "tags": "a, b", "name": "x",
+	}
+	updated := map[string]interface{}{
+		"port": 8080, "debug": true, "tags": []interface{}{"a", "b"}, "name": "y",
+	}
+
+	if changes := Compare(old, updated); len(changes) != 4 {
+		t.Errorf("строгое сравнение: %d изменений, ожидалось 4", len(changes))
+	}
+
+	d := NewDiffer()
+	d.Loose = true
+	changes := d.Compare(old, updated)
+	if len(changes) != 1 || changes[0].Path != "name" {
+		t.Errorf("нестрогое сравнение: %v", changes)
+	}
+}
+
+func TestPatch(t *testing.T) {
+	changes := Compare(
+		map[string]interface{}{"a": map[string]interface{}{"b/c": 1, "x": 1}, "l": []interface{}{1, 2}},
+		map[string]interface{}{"a": map[string]interface{}{"b/c": 2, "y": 1}, "l": []interface{}{1}},
+	)
+	ops, err := Patch(changes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := patch.Patch{
+		{Op: patch.OpReplace, Path: "/a/b~1c", Value: 2},
+		{Op: patch.OpRemove, Path: "/a/x"},
+		{Op: patch.OpAdd, Path: "/a/y", Value: 1},
+		{Op: patch.OpRemove, Path: "/l/1"},
+	}
+	if !reflect.DeepEqual(ops, want) {
+		t.Errorf("получено %+v", ops)
+	}
+}
+
+func TestFiles(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, data string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	json := write("a.json", `{"server": {"port": 8080, "host": "x"}}`)
+	yaml := write("b.yaml", "server:\n  port: 8080\n  host: y\n")
+	ini := write("c.ini", "[server]\nport = 8080\nhost = x\n")
+
+	changes, err := Files(json, yaml)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].Path != "server.host" {
+		t.Errorf("JSON и YAML: %v", changes)
+	}
+
+	// INI сравнивается нестрого: "8080" равно 8080
+	changes, err = Files(json, ini)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("JSON и INI: %v", changes)
+	}
+
+	if _, err := Files(json, filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("нет файла: ожидалась ошибка")
+	}
+}
+
+func TestPatchEmptyKey(t *testing.T) {
+	old := map[string]interface{}{"": 1.0, "a": map[string]interface{}{"": []interface{}{1.0}}}
+	updated := map[string]interface{}{"": 2.0, "a": map[string]interface{}{"": []interface{}{1.0, 3.0}}}
+
+	changes := Compare(old, updated)
+	var paths []string
+	for _, c := range changes {
+		paths = append(paths, c.Path)
+	}
+	if want := []string{`[""]`, `a[""][1]`}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("пути = %q, ожидалось %q", paths, want)
+	}
+
+	ops, err := Patch(changes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := patch.Patch{
+		{Op: patch.OpReplace, Path: "/", Value: 2.0},
+		{Op: patch.OpAdd, Path: "/a//1", Value: 3.0},
+	}
+	if !reflect.DeepEqual(ops, want) {
+		t.Fatalf("получено %+v", ops)
+	}
+
+	// Патч меняет только пустой ключ, а не весь документ
+	got, err := ops.Apply(old)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, updated) {
+		t.Errorf("после патча %v, ожидалось %v", got, updated)
+	}
+}
@@ -0,0 +1,373 @@
+package document
+
+// document.go
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/keypath"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/parsers"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/types"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/utils"
+)
+
+// Document редактируемый файл конфигурации, сохраняющий комментарии,
+// порядок ключей и пустые строки. Set меняет только байты изменяемого
+// значения (или вставляет новые строки), остальной файл не меняется
+type Document interface {
+	Format() types.ConfigFormat
+	// Get возвращает текущее значение по пути
+	Get(path string) (interface{}, bool)
+	// Set записывает значение по пути, создавая недостающие ключи и секции
+	Set(path string, value interface{}) error
+	// Bytes возвращает текущее содержимое файла
+	Bytes() []byte
+}
+
+// ErrRemovedKeys возвращается Rewrite, если в новом значении отсутствуют
+// ключи исходного файла: удаление ключей документ не выполняет
+var ErrRemovedKeys = errors.New("в новом значении удалены ключи исходного файла")
+
+// Supports сообщает, поддерживается ли редактирование формата с сохранением разметки
+func Supports(format types.ConfigFormat) bool {
+	switch format {
+	case types.FormatYAML, types.FormatINI, types.FormatTOML:
+		return true
+	default:
+		return false
+	}
+}
+
+// Parse создает документ из данных указанного формата
+func Parse(format types.ConfigFormat, data []byte) (Document, error) {
+	if _, err := parsers.ParseDynamic(format, data); err != nil {
+		return nil, err
+	}
+
+	b := base{format: format, data: append([]byte(nil), data...)}
+	switch format {
+	case types.FormatYAML:
+		return &yamlDocument{base: b}, nil
+	case types.FormatINI:
+		return &iniDocument{base: b}, nil
+	case types.FormatTOML:
+		return &tomlDocument{base: b}, nil
+	default:
+		return nil, &types.UnsupportedFormatError{Format: format}
+	}
+}
+
+// Load читает файл и создает документ, определяя формат как parsers.LoadFile
+func Load(path string) (Document, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать файл %s: %w", path, err)
+	}
+
+	format, err := parsers.DetectFormat(path, data)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := Parse(format, data)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось распарсить %s из %s: %w", format, path, err)
+	}
+	return doc, nil
+}
+
+// Rewrite переносит в данные файла изменения value относительно
+// их текущего содержимого: каждое измененное или новое значение
+// записывается через Set, поэтому остальная разметка сохраняется.
+// Если из value удалены ключи, возвращается ErrRemovedKeys
+func Rewrite(format types.ConfigFormat, data []byte, value interface{}) ([]byte, error) {
+	doc, err := Parse(format, data)
+	if err != nil {
+		return nil, err
+	}
+
+	old, err := parsers.ParseDynamic(format, data)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, ok := keypath.Normalize(value).(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("значение типа %T не является объектом", value)
+	}
+
+	var changes []change
+	if removed := diff("", old, updated, format, &changes); removed {
+		return nil, ErrRemovedKeys
+	}
+
+	for _, c := range changes {
+		if err := doc.Set(c.path, c.value); err != nil {
+			return nil, err
+		}
+	}
+	return doc.Bytes(), nil
+}
+
+// change одно изменение для Rewrite
+type change struct {
+	path  string
+	value interface{}
+}
+
+// diff собирает изменения от old к updated и сообщает об удаленных ключах.
+// Объекты сравниваются по ключам, массивы объектов одинаковой длины - по элементам,
+// остальные значения заменяются целиком
+func diff(prefix string, old, updated interface{}, format types.ConfigFormat, changes *[]change) bool {
+	switch o := old.(type) {
+	case map[string]interface{}:
+		u, ok := updated.(map[string]interface{})
+		if !ok {
+			break
+		}
+		removed := false
+		for key := range o {
+			if _, exists := u[key]; !exists {
+				removed = true
+			}
+		}
+		for _, key := range utils.SortedKeys(u) {
+			path := keypath.Join(prefix, key)
+			if value, exists := o[key]; exists {
+				removed = diff(path, value, u[key], format, changes) || removed
+			} else {
+				*changes = append(*changes, change{path, u[key]})
+			}
+		}
+		return removed
+
+	case []interface{}:
+		u, ok := updated.([]interface{})
+		if !ok || len(u) != len(o) || !allMaps(o) || !allMaps(u) {
+			break
+		}
+		removed := false
+		for i := range o {
+			removed = diff(keypath.Index(prefix, i), o[i], u[i], format, changes) || removed
+		}
+		return removed
+	}
+
+	if !equalValues(old, updated, format) {
+		*changes = append(*changes, change{prefix, updated})
+	}
+	return false
+}
+
+// allMaps проверяет, что все элементы массива - объекты
+func allMaps(arr []interface{}) bool {
+	for _, item := range arr {
+		if _, ok := item.(map[string]interface{}); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// equalValues сравнивает значения без учета представления чисел
+// (int, int64, float64) и, для INI, строкового представления скаляров
+func equalValues(a, b interface{}, format types.ConfigFormat) bool {
+	if reflect.DeepEqual(a, b) {
+		return true
+	}
+	if format == types.FormatINI {
+		if s, ok := a.(string); ok {
+			if text, err := iniValue(b); err == nil && text == s {
+				return true
+			}
+		}
+	}
+	return canonical(a) == canonical(b)
+}
+
+// canonical возвращает JSON-представление значения для сравнения
+func canonical(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%#v", v)
+	}
+	return string(data)
+}
+
+// base общая часть документов: данные и проверка результата правки
+type base struct {
+	format types.ConfigFormat
+	data   []byte
+}
+
+func (b *base) Format() types.ConfigFormat {
+	return b.format
+}
+
+func (b *base) Bytes() []byte {
+	return append([]byte(nil), b.data...)
+}
+
+func (b *base) Get(path string) (interface{}, bool) {
+	value, err := parsers.ParseDynamic(b.format, b.data)
+	if err != nil {
+		return nil, false
+	}
+	return keypath.Get(value, path)
+}
+
+// commit принимает отредактированные данные, если они разбираются
+// и по пути действительно находится записанное значение
+func (b *base) commit(edited []byte, path keypath.Path, value interface{}) error {
+	parsed, err := parsers.ParseDynamic(b.format, edited)
+	if err != nil {
+		return fmt.Errorf("правка '%s' нарушила синтаксис %s: %w", path, b.format, err)
+	}
+
+	matches := path.Find(parsed)
+	if len(matches) == 0 || !equalValues(matches[0].Value, value, b.format) {
+		return fmt.Errorf("правка '%s' не дала ожидаемого значения в %s", path, b.format)
+	}
+
+	b.data = edited
+	return nil
+}
+
+// editablePath разбирает путь, пригодный для Set
+func editablePath(key string) (keypath.Path, error) {
+	path, err := keypath.Parse(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(path) == 0 {
+		return nil, fmt.Errorf("пустой путь")
+	}
+	if path.HasWildcard() {
+		return nil, fmt.Errorf("путь '%s' содержит шаблон и не может использоваться для изменения", key)
+	}
+	return path, nil
+}
+
+// build строит значение, которое нужно записать вместо отсутствующего
+// хвоста пути rest: Set("a.b", 1) для отсутствующего a дает {"b": 1}
+func build(rest keypath.Path, value interface{}) interface{} {
+	if len(rest) == 0 {
+		return value
+	}
+	holder := map[string]interface{}{}
+	full := append(keypath.Path{{Kind: keypath.KindKey, Key: "_"}}, rest...)
+	_ = full.Set(holder, value)
+	return holder["_"]
+}
+
+// splice заменяет data[start:end] текстом
+func splice(data []byte, start, end int, text string) []byte {
+	var buf bytes.Buffer
+	buf.Grow(len(data) - (end - start) + len(text))
+	buf.Write(data[:start])
+	buf.WriteString(text)
+	buf.Write(data[end:])
+	return buf.Bytes()
+}
+
+// lines индекс начал строк для перевода позиций в смещения
+type lines struct {
+	data   []byte
+	starts []int
+}
+
+func newLines(data []byte) *lines {
+	starts := []int{0}
+	for i, c := range data {
+		if c == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+	return &lines{data: data, starts: starts}
+}
+
+// count возвращает число строк
+func (l *lines) count() int {
+	return len(l.starts)
+}
+
+// text возвращает строку n (с нуля) без перевода строки
+func (l *lines) text(n int) string {
+	return string(l.data[l.starts[n]:l.end(n)])
+}
+
+// end возвращает смещение конца строки n без \r\n
+func (l *lines) end(n int) int {
+	end := len(l.data)
+	if n+1 < len(l.starts) {
+		end = l.starts[n+1] - 1
+	}
+	if end > l.starts[n] && l.data[end-1] == '\r' {
+		end--
+	}
+	return end
+}
+
+// line возвращает номер строки (с нуля), содержащей смещение
+func (l *lines) line(offset int) int {
+	return sort.Search(len(l.starts), func(i int) bool { return l.starts[i] > offset }) - 1
+}
+
+// newline возвращает используемый в файле перевод строки
+func (l *lines) newline() string {
+	if bytes.Contains(l.data, []byte("\r\n")) {
+		return "\r\n"
+	}
+	return "\n"
+}
+
+// indent возвращает ширину отступа строки
+func indent(text string) int {
+	return len(text) - len(trimLeft(text))
+}
+
+func trimLeft(text string) string {
+	for i, c := range text {
+		if c != ' ' && c != '\t' {
+			return text[i:]
+		}
+	}
+	return ""
+}
+
+// withTrailingNewline добавляет перевод строки в конец непустых данных
+func withTrailingNewline(data []byte, nl string) []byte {
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		return append(data, nl...)
+	}
+	return data
+}
+
+// absolute заменяет отрицательные индексы пути на индексы от начала массива
+func (b *base) absolute(path keypath.Path) keypath.Path {
+	value, err := parsers.ParseDynamic(b.format, b.data)
+	if err != nil {
+		return path
+	}
+
+	result := append(keypath.Path(nil), path...)
+	for i, seg := range result {
+		if seg.Kind != keypath.KindIndex || seg.Index >= 0 {
+			continue
+		}
+		matches := result[:i].Find(value)
+		if len(matches) == 0 {
+			break
+		}
+		if arr, ok := matches[0].Value.([]interface{}); ok && seg.Index+len(arr) >= 0 {
+			result[i].Index = seg.Index + len(arr)
+		}
+	}
+	return result
+}
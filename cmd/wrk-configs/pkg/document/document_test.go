@@ -0,0 +1,231 @@
+package document
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/parsers"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/types"
+)
+
+// set одно изменение для Set
+type set struct {
+	path  string
+	value interface{}
+}
+
+// setTests Set должен менять только байты значений, сохраняя комментарии,
+// пустые строки, разделители и порядок ключей
+var setTests = []struct {
+	name   string
+	format types.ConfigFormat
+	src    string
+	sets   []set
+	want   string
+}{
+	{
+		name:   "yaml",
+		format: types.FormatYAML,
+		src: "# top\n" +
+			"server:\n" +
+			"  host: localhost # inline\n" +
+			"  port: 8080\n" +
+			"\n" +
+			"  tags: [a, b]\n" +
+			"list:\n" +
+			"  - 1\n" +
+			"  - 2\n",
+		sets: []set{
+			{"server.port", 9090},
+			{"server.host", "example.com"},
+			{"server.tags", []interface{}{"x"}},
+			{"list[1]", 5},
+			{"server.new", true},
+			{"db.name", "app"},
+		},
+		want: "# top\n" +
+			"server:\n" +
+			"  host: example.com # inline\n" +
+			"  port: 9090\n" +
+			"\n" +
+			"  tags: [x]\n" +
+			"  new: true\n" +
+			"list:\n" +
+			"  - 1\n" +
+			"  - 5\n" +
+			"db:\n" +
+			"  name: app\n",
+	},
+	{
+		name:   "ini",
+		format: types.FormatINI,
+		src: "; top\n" +
+			"[server]\n" +
+			"host = localhost ; c\n" +
+			"port=8080\n" +
+			"\n" +
+			"[db]\n" +
+			"name = x\n",
+		sets: []set{
+			{"server.port", 9090},
+			{"server.new", "v"},
+			{"cache.ttl", 5},
+			{"db.name", "y z"},
+		},
+		want: "; top\n" +
+			"[server]\n" +
+			"host = localhost ; c\n" +
+			"port=9090\n" +
+			"new=v\n" +
+			"\n" +
+			"[db]\n" +
+			"name = y z\n" +
+			"\n" +
+			"[cache]\n" +
+			"ttl = 5\n",
+	},
+	{
+		name:   "toml",
+		format: types.FormatTOML,
+		src: "# top\n" +
+			"title = \"t\"\n" +
+			"[server]\n" +
+			"host = \"localhost\" # c\n" +
+			"port = 8080\n" +
+			"\n" +
+			"[[servers]]\n" +
+			"name = \"a\"\n",
+		sets: []set{
+			{"server.port", 9090},
+			{"title", "new"},
+			{"servers[0].name", "b"},
+			{"server.new", []interface{}{1, 2}},
+			{"db.name", "x"},
+		},
+		want: "# top\n" +
+			"title = \"new\"\n" +
+			"[server]\n" +
+			"host = \"localhost\" # c\n" +
+			"port = 9090\n" +
+			"new = [1, 2]\n" +
+			"\n" +
+			"[[servers]]\n" +
+			"name = \"b\"\n" +
+			"\n" +
+			"[db]\n" +
+			"name = \"x\"\n",
+	},
+}
+
+func TestSetPreservesLayout(t *testing.T) {
+	for _, tt := range setTests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := Parse(tt.format, []byte(tt.src))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, s := range tt.sets {
+				if err := doc.Set(s.path, s.value); err != nil {
+					t.Fatalf("Set(%q): %v", s.path, err)
+				}
+			}
+
+			if got := string(doc.Bytes()); got != tt.want {
+				t.Errorf("получено:\n%s\nожидалось:\n%s", got, tt.want)
+			}
+			// Результат должен разбираться тем же парсером
+			if _, err := parsers.ParseDynamic(tt.format, doc.Bytes()); err != nil {
+				t.Errorf("результат не разбирается: %v", err)
+			}
+		})
+	}
+}
+
+func TestGetAfterSet(t *testing.T) {
+	for _, tt := range setTests {
+		doc, err := Parse(tt.format, []byte(tt.src))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := doc.Set("server.port", 1234); err != nil {
+			t.Fatal(err)
+		}
+		value, ok := doc.Get("server.port")
+		if !ok {
+			t.Errorf("%s: server.port не найден", tt.name)
+			continue
+		}
+		// INI хранит все значения строками
+		if value != int64(1234) && value != 1234 && value != "1234" {
+			t.Errorf("%s: server.port = %#v", tt.name, value)
+		}
+	}
+}
+
+func TestRewrite(t *testing.T) {
+	src := "# comment\nserver:\n  host: localhost\n  port: 8080 # port\n"
+
+	t.Run("без изменений", func(t *testing.T) {
+		value, _ := parsers.ParseDynamic(types.FormatYAML, []byte(src))
+		got, err := Rewrite(types.FormatYAML, []byte(src), value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != src {
+			t.Errorf("получено:\n%s", got)
+		}
+	})
+
+	t.Run("изменение и новый ключ", func(t *testing.T) {
+		value := map[string]interface{}{
+			"server": map[string]interface{}{"host": "localhost", "port": 80, "debug": true},
+		}
+		got, err := Rewrite(types.FormatYAML, []byte(src), value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := "# comment\nserver:\n  host: localhost\n  port: 80 # port\n  debug: true\n"
+		if string(got) != want {
+			t.Errorf("получено:\n%s\nожидалось:\n%s", got, want)
+		}
+	})
+
+	t.Run("структура", func(t *testing.T) {
+		type server struct {
+			Host string `json:"host"`
+			Port int    `json:"port"`
+		}
+		value := struct {
+			Server server `json:"server"`
+		}{server{Host: "example.com", Port: 8080}}
+		got, err := Rewrite(types.FormatYAML, []byte(src), value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed, _ := parsers.ParseDynamic(types.FormatYAML, got)
+		want := map[string]interface{}{"server": map[string]interface{}{"host": "example.com", "port": 8080}}
+		if !reflect.DeepEqual(parsed, want) {
+			t.Errorf("получено %v", parsed)
+		}
+	})
+
+	t.Run("удаленный ключ", func(t *testing.T) {
+		value := map[string]interface{}{"server": map[string]interface{}{"host": "localhost"}}
+		if _, err := Rewrite(types.FormatYAML, []byte(src), value); !errors.Is(err, ErrRemovedKeys) {
+			t.Errorf("ожидалась ErrRemovedKeys, получено %v", err)
+		}
+	})
+}
+
+func TestParseUnsupported(t *testing.T) {
+	if Supports(types.FormatJSON) {
+		t.Error("JSON не редактируется документом")
+	}
+	if _, err := Parse(types.FormatJSON, []byte(`{}`)); err == nil {
+		t.Error("Parse(JSON): ожидалась ошибка")
+	}
+	if _, err := Parse(types.FormatYAML, []byte("a: [")); err == nil {
+		t.Error("Parse некорректного YAML: ожидалась ошибка")
+	}
+}
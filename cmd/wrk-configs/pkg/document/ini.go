@@ -0,0 +1,235 @@
+package document
+
+// ini.go
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/generators"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/keypath"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/types"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/utils"
+	"gopkg.in/ini.v1"
+)
+
+// iniDocument редактирует INI построчно. Путь "a.b.key" означает
+// ключ key секции [a.b], ключи без секции относятся к секции по умолчанию
+type iniDocument struct {
+	base
+}
+
+// iniEntry строка ключа INI
+type iniEntry struct {
+	section  string
+	key      string
+	line     int
+	valStart int
+	valEnd   int
+}
+
+// iniSection секция INI: строка заголовка (-1 для секции по умолчанию)
+// и последняя строка с ключом
+type iniSection struct {
+	header int
+	last   int
+}
+
+// iniScan разбирает строки INI на секции и ключи
+func iniScan(l *lines) ([]iniEntry, map[string]*iniSection, []string) {
+	var entries []iniEntry
+	sections := map[string]*iniSection{"": {header: -1, last: -1}}
+	order := []string{""}
+	section := ""
+
+	for n := 0; n < l.count(); n++ {
+		text := l.text(n)
+		trimmed := strings.TrimSpace(text)
+
+		switch {
+		case trimmed == "" || trimmed[0] == ';' || trimmed[0] == '#':
+		case trimmed[0] == '[':
+			end := strings.Index(trimmed, "]")
+			if end < 0 {
+				continue
+			}
+			section = strings.TrimSpace(trimmed[1:end])
+			if section == ini.DefaultSection {
+				section = ""
+			}
+			if _, exists := sections[section]; !exists {
+				sections[section] = &iniSection{header: n, last: -1}
+				order = append(order, section)
+			}
+		default:
+			delim := strings.IndexAny(text, "=:")
+			if delim < 0 {
+				continue
+			}
+			key := strings.Trim(strings.TrimSpace(text[:delim]), "\"`")
+			start := l.starts[n] + delim + 1
+			valStart, valEnd := iniValueSpan(l.data[start:l.end(n)])
+			entries = append(entries, iniEntry{
+				section:  section,
+				key:      key,
+				line:     n,
+				valStart: start + valStart,
+				valEnd:   start + valEnd,
+			})
+			sections[section].last = n
+		}
+	}
+
+	return entries, sections, order
+}
+
+// iniValueSpan находит значение после разделителя так же, как ini.v1:
+// значение в обратных кавычках или """ целиком, иначе до комментария # или ;
+func iniValueSpan(raw []byte) (int, int) {
+	text := string(raw)
+	start := len(text) - len(trimLeft(text))
+	value := text[start:]
+
+	for _, quote := range []string{`"""`, "`"} {
+		if strings.HasPrefix(value, quote) {
+			if end := strings.LastIndex(value[len(quote):], quote); end >= 0 {
+				return start, start + len(quote) + end + len(quote)
+			}
+		}
+	}
+
+	if cut := strings.IndexAny(value, "#;"); cut >= 0 {
+		value = value[:cut]
+	}
+	return start, start + len(strings.TrimRight(value, " \t"))
+}
+
+// iniValue форматирует значение так же, как generators.INIGenerator,
+// включая кавычки для значений с символами комментариев
+func iniValue(value interface{}) (string, error) {
+	switch value.(type) {
+	case map[string]interface{}:
+		return "", fmt.Errorf("INI не поддерживает вложенные объекты в значении")
+	}
+
+	data, err := generators.Generate(types.FormatINI, map[string]interface{}{"k": value})
+	if err != nil {
+		return "", err
+	}
+	text := strings.TrimRight(string(data), "\n")
+	_, after, ok := strings.Cut(text, "=")
+	if !ok {
+		return "", fmt.Errorf("не удалось сформировать значение INI для %v", value)
+	}
+	return strings.TrimSpace(after), nil
+}
+
+func (d *iniDocument) Set(key string, value interface{}) error {
+	path, err := editablePath(key)
+	if err != nil {
+		return err
+	}
+	value = keypath.Normalize(value)
+
+	// Объект записывается поключно: секции INI не бывают значениями
+	if obj, ok := value.(map[string]interface{}); ok {
+		for _, child := range utils.SortedKeys(obj) {
+			if err := d.Set(keypath.Join(path.String(), child), obj[child]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, seg := range path {
+		if seg.Kind != keypath.KindKey {
+			return fmt.Errorf("путь '%s': INI не поддерживает индексы массивов", key)
+		}
+	}
+
+	text, err := iniValue(value)
+	if err != nil {
+		return fmt.Errorf("путь '%s': %w", key, err)
+	}
+
+	section := path[:len(path)-1].String()
+	name := path[len(path)-1].Key
+	if section != "" {
+		// Имена секций хранятся без экранирования: [paths.conf.d]
+		parts := make([]string, len(path)-1)
+		for i, seg := range path[:len(path)-1] {
+			parts[i] = seg.Key
+		}
+		section = strings.Join(parts, ".")
+	}
+
+	edited := d.edit(section, name, text)
+	return d.commit(edited, path, value)
+}
+
+// edit заменяет значение существующего ключа или вставляет новый
+func (d *iniDocument) edit(section, name, text string) []byte {
+	l := newLines(d.data)
+	nl := l.newline()
+	entries, sections, order := iniScan(l)
+
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		if e.section == section && e.key == name {
+			return splice(d.data, e.valStart, e.valEnd, text)
+		}
+	}
+
+	s, exists := sections[section]
+	if !exists {
+		data := withTrailingNewline(d.data, nl)
+		prefix := ""
+		if len(data) > 0 {
+			prefix = nl
+		}
+		return append(data, prefix+"["+section+"]"+nl+name+d.delimiter(l, entries, section)+text+nl...)
+	}
+
+	line := name + d.delimiter(l, entries, section) + text
+
+	switch {
+	case s.last >= 0:
+		// После последнего ключа секции
+		return splice(d.data, l.end(s.last), l.end(s.last), nl+line)
+	case s.header >= 0:
+		// Сразу после заголовка пустой секции
+		return splice(d.data, l.end(s.header), l.end(s.header), nl+line)
+	default:
+		// Секция по умолчанию без ключей: перед первой секцией
+		for _, other := range order {
+			if h := sections[other].header; h >= 0 {
+				return splice(d.data, l.starts[h], l.starts[h], line+nl+nl)
+			}
+		}
+		return append(withTrailingNewline(d.data, nl), line+nl...)
+	}
+}
+
+// delimiter повторяет оформление разделителя ("key = value" или "key=value")
+// последнего ключа секции, а если его нет - последнего ключа файла
+func (d *iniDocument) delimiter(l *lines, entries []iniEntry, section string) string {
+	if len(entries) == 0 {
+		return " = "
+	}
+
+	e := entries[len(entries)-1]
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].section == section {
+			e = entries[i]
+			break
+		}
+	}
+
+	text := string(d.data[l.starts[e.line]:e.valStart])
+	if cut := strings.Index(text, e.key); cut >= 0 {
+		if delim := strings.TrimLeft(text[cut+len(e.key):], "\"`"); delim != "" {
+			return delim
+		}
+	}
+	return " = "
+}
@@ -0,0 +1,503 @@
+package document
+
+// toml.go
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/keypath"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/utils"
+	"github.com/pelletier/go-toml/v2"
+)
+
+// tomlDocument редактирует TOML по строкам, находя точные границы значений
+// (включая многострочные строки и массивы). Значения внутри inline-таблиц
+// и массивов меняются переписыванием всего значения ключа
+type tomlDocument struct {
+	base
+}
+
+// tomlEntry пара ключ = значение с полным путем
+type tomlEntry struct {
+	path     keypath.Path
+	table    int
+	valStart int
+	valEnd   int
+}
+
+// tomlTable таблица: путь, строка заголовка (-1 для корня)
+// и строка конца последнего значения (-1, если ключей нет)
+type tomlTable struct {
+	path   keypath.Path
+	header int
+	last   int
+}
+
+// tomlScan разбирает документ на таблицы и ключи
+func tomlScan(l *lines) ([]tomlEntry, []tomlTable, error) {
+	var entries []tomlEntry
+	tables := []tomlTable{{header: -1, last: -1}}
+	counts := make(map[string]int)
+	current := 0
+
+	// resolve переводит части заголовка в путь: родительские
+	// массивы таблиц указывают на свой последний элемент
+	resolve := func(parts []string) keypath.Path {
+		var path keypath.Path
+		for i, part := range parts {
+			path = append(path, keypath.Segment{Kind: keypath.KindKey, Key: part})
+			if i == len(parts)-1 {
+				break
+			}
+			if count := counts[path.String()]; count > 0 {
+				path = append(path, keypath.Segment{Kind: keypath.KindIndex, Index: count - 1})
+			}
+		}
+		return path
+	}
+
+	for n := 0; n < l.count(); n++ {
+		text := l.text(n)
+		trimmed := strings.TrimSpace(text)
+
+		switch {
+		case trimmed == "" || trimmed[0] == '#':
+		case strings.HasPrefix(trimmed, "[["):
+			end := strings.Index(trimmed, "]]")
+			if end < 0 {
+				return nil, nil, fmt.Errorf("строка %d: незакрытый заголовок массива таблиц", n+1)
+			}
+			parts, err := tomlKeyParts(trimmed[2:end])
+			if err != nil {
+				return nil, nil, fmt.Errorf("строка %d: %w", n+1, err)
+			}
+			path := resolve(parts)
+			index := counts[path.String()]
+			counts[path.String()] = index + 1
+			path = append(path, keypath.Segment{Kind: keypath.KindIndex, Index: index})
+			tables = append(tables, tomlTable{path: path, header: n, last: -1})
+			current = len(tables) - 1
+		case trimmed[0] == '[':
+			end := strings.Index(trimmed, "]")
+			if end < 0 {
+				return nil, nil, fmt.Errorf("строка %d: незакрытый заголовок таблицы", n+1)
+			}
+			parts, err := tomlKeyParts(trimmed[1:end])
+			if err != nil {
+				return nil, nil, fmt.Errorf("строка %d: %w", n+1, err)
+			}
+			tables = append(tables, tomlTable{path: resolve(parts), header: n, last: -1})
+			current = len(tables) - 1
+		default:
+			eq := tomlKeyEnd(text)
+			if eq < 0 {
+				return nil, nil, fmt.Errorf("строка %d: ожидается ключ = значение", n+1)
+			}
+			parts, err := tomlKeyParts(text[:eq])
+			if err != nil {
+				return nil, nil, fmt.Errorf("строка %d: %w", n+1, err)
+			}
+
+			path := append(keypath.Path(nil), tables[current].path...)
+			for _, part := range parts {
+				path = append(path, keypath.Segment{Kind: keypath.KindKey, Key: part})
+			}
+
+			start := l.starts[n] + eq + 1
+			for start < len(l.data) && (l.data[start] == ' ' || l.data[start] == '\t') {
+				start++
+			}
+			end := tomlValueEnd(l.data, start)
+
+			entries = append(entries, tomlEntry{path: path, table: current, valStart: start, valEnd: end})
+
+			// Многострочное значение: продолжаем после его последней строки
+			n = l.line(end)
+			tables[current].last = n
+		}
+	}
+
+	return entries, tables, nil
+}
+
+// tomlKeyEnd возвращает позицию = вне кавычек
+func tomlKeyEnd(text string) int {
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '=':
+			return i
+		}
+	}
+	return -1
+}
+
+// tomlKeyParts разбирает ключ с точками и кавычками: a."b.c".d -> [a b.c d]
+func tomlKeyParts(raw string) ([]string, error) {
+	var parts []string
+	s := strings.TrimSpace(raw)
+
+	for {
+		s = strings.TrimLeft(s, " \t")
+		var part string
+
+		switch {
+		case strings.HasPrefix(s, `"`):
+			end := 1
+			for end < len(s) && s[end] != '"' {
+				if s[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(s) {
+				return nil, fmt.Errorf("незакрытая кавычка в ключе %q", raw)
+			}
+			unquoted, err := strconv.Unquote(s[:end+1])
+			if err != nil {
+				return nil, fmt.Errorf("неверный ключ %q: %w", raw, err)
+			}
+			part, s = unquoted, s[end+1:]
+		case strings.HasPrefix(s, "'"):
+			end := strings.IndexByte(s[1:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("незакрытая кавычка в ключе %q", raw)
+			}
+			part, s = s[1:end+1], s[end+2:]
+		default:
+			end := strings.IndexAny(s, ". \t")
+			if end < 0 {
+				end = len(s)
+			}
+			part, s = s[:end], s[end:]
+		}
+
+		if part == "" && !strings.HasPrefix(raw, `"`) && !strings.HasPrefix(raw, "'") {
+			return nil, fmt.Errorf("пустой ключ %q", raw)
+		}
+		parts = append(parts, part)
+
+		s = strings.TrimLeft(s, " \t")
+		if s == "" {
+			return parts, nil
+		}
+		if s[0] != '.' {
+			return nil, fmt.Errorf("неверный ключ %q", raw)
+		}
+		s = s[1:]
+	}
+}
+
+// tomlValueEnd возвращает смещение конца значения, начинающегося в start
+func tomlValueEnd(data []byte, start int) int {
+	rest := data[start:]
+
+	switch {
+	case bytes.HasPrefix(rest, []byte(`"""`)), bytes.HasPrefix(rest, []byte(`'''`)):
+		return tomlSkipString(data, start)
+	case len(rest) > 0 && (rest[0] == '"' || rest[0] == '\''):
+		return tomlSkipString(data, start)
+	case len(rest) > 0 && (rest[0] == '[' || rest[0] == '{'):
+		depth := 0
+		for i := start; i < len(data); {
+			switch data[i] {
+			case '"', '\'':
+				i = tomlSkipString(data, i)
+				continue
+			case '#':
+				for i < len(data) && data[i] != '\n' {
+					i++
+				}
+				continue
+			case '[', '{':
+				depth++
+			case ']', '}':
+				depth--
+				if depth == 0 {
+					return i + 1
+				}
+			}
+			i++
+		}
+		return len(data)
+	}
+
+	// Число, булево значение или дата: до комментария или конца строки
+	end := start
+	for end < len(data) && data[end] != '\n' && data[end] != '#' {
+		end++
+	}
+	for end > start && (data[end-1] == ' ' || data[end-1] == '\t' || data[end-1] == '\r') {
+		end--
+	}
+	return end
+}
+
+// tomlSkipString возвращает смещение после строки, начинающейся в i
+func tomlSkipString(data []byte, i int) int {
+	quote := data[i]
+	multi := bytes.HasPrefix(data[i:], []byte{quote, quote, quote})
+
+	if multi {
+		delim := []byte{quote, quote, quote}
+		for j := i + 3; j < len(data); j++ {
+			if quote == '"' && data[j] == '\\' {
+				j++
+				continue
+			}
+			if bytes.HasPrefix(data[j:], delim) {
+				end := j + 3
+				// До двух кавычек могут относиться к содержимому: """a"""""
+				for k := 0; k < 2 && end < len(data) && data[end] == quote; k++ {
+					end++
+				}
+				return end
+			}
+		}
+		return len(data)
+	}
+
+	for j := i + 1; j < len(data) && data[j] != '\n'; j++ {
+		if quote == '"' && data[j] == '\\' {
+			j++
+			continue
+		}
+		if data[j] == quote {
+			return j + 1
+		}
+	}
+	return len(data)
+}
+
+var tomlBareKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// tomlKeyText форматирует часть ключа: без кавычек, если это возможно
+func tomlKeyText(key string) string {
+	if tomlBareKey.MatchString(key) {
+		return key
+	}
+	return tomlBasicString(key)
+}
+
+// tomlPathText форматирует путь из ключей через точку
+func tomlPathText(path keypath.Path) string {
+	parts := make([]string, len(path))
+	for i, seg := range path {
+		parts[i] = tomlKeyText(seg.Key)
+	}
+	return strings.Join(parts, ".")
+}
+
+// tomlBasicString записывает строку в двойных кавычках.
+// Экранирование JSON совместимо с базовыми строками TOML
+func tomlBasicString(s string) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+	return strings.TrimRight(buf.String(), "\n")
+}
+
+// tomlValue форматирует значение TOML в одну строку. quote - кавычка
+// исходного значения: строки сохраняют стиль, если это возможно
+func tomlValue(value interface{}, quote byte) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", fmt.Errorf("TOML не поддерживает null")
+	case string:
+		if quote == '\'' && !strings.ContainsAny(v, "'\n\r") {
+			return "'" + v + "'", nil
+		}
+		return tomlBasicString(v), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprint(v), nil
+	case float32:
+		return tomlValue(float64(v), quote)
+	case float64:
+		switch {
+		case math.IsNaN(v):
+			return "nan", nil
+		case math.IsInf(v, 1):
+			return "inf", nil
+		case math.IsInf(v, -1):
+			return "-inf", nil
+		case v == math.Trunc(v) && math.Abs(v) < 1<<53:
+			return strconv.FormatInt(int64(v), 10), nil
+		}
+		return strconv.FormatFloat(v, 'g', -1, 64), nil
+	case time.Time:
+		return v.Format(time.RFC3339Nano), nil
+	case toml.LocalDate, toml.LocalTime, toml.LocalDateTime:
+		return fmt.Sprint(v), nil
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			text, err := tomlValue(item, quote)
+			if err != nil {
+				return "", err
+			}
+			items[i] = text
+		}
+		return "[" + strings.Join(items, ", ") + "]", nil
+	case map[string]interface{}:
+		if len(v) == 0 {
+			return "{}", nil
+		}
+		keys := utils.SortedKeys(v)
+		items := make([]string, len(keys))
+		for i, key := range keys {
+			text, err := tomlValue(v[key], quote)
+			if err != nil {
+				return "", err
+			}
+			items[i] = tomlKeyText(key) + " = " + text
+		}
+		return "{ " + strings.Join(items, ", ") + " }", nil
+	}
+	return "", fmt.Errorf("TOML не поддерживает значения типа %T", value)
+}
+
+func (d *tomlDocument) Set(key string, value interface{}) error {
+	path, err := editablePath(key)
+	if err != nil {
+		return err
+	}
+	return d.set(d.absolute(path), keypath.Normalize(value))
+}
+
+func (d *tomlDocument) set(path keypath.Path, value interface{}) error {
+	l := newLines(d.data)
+	entries, tables, err := tomlScan(l)
+	if err != nil {
+		return err
+	}
+	target := path.String()
+
+	// Значение существующего ключа
+	for _, e := range entries {
+		if e.path.String() == target {
+			text, err := tomlValue(value, d.data[e.valStart])
+			if err != nil {
+				return fmt.Errorf("путь '%s': %w", target, err)
+			}
+			return d.commit(splice(d.data, e.valStart, e.valEnd, text), path, value)
+		}
+	}
+
+	// Путь внутри inline-таблицы или массива: переписываем значение ключа целиком
+	for _, e := range entries {
+		if hasPrefix(path, e.path) {
+			var holder map[string]interface{}
+			if err := toml.Unmarshal([]byte("v = "+string(d.data[e.valStart:e.valEnd])), &holder); err != nil {
+				return fmt.Errorf("путь '%s': %w", e.path, err)
+			}
+			inner := append(keypath.Path{{Kind: keypath.KindKey, Key: "v"}}, path[len(e.path):]...)
+			if err := inner.Set(holder, value); err != nil {
+				return fmt.Errorf("путь '%s': %w", target, err)
+			}
+			text, err := tomlValue(holder["v"], d.data[e.valStart])
+			if err != nil {
+				return fmt.Errorf("путь '%s': %w", target, err)
+			}
+			return d.commit(splice(d.data, e.valStart, e.valEnd, text), path, value)
+		}
+	}
+
+	// Таблица или новый объект записываются поключно
+	if obj, ok := value.(map[string]interface{}); ok && len(obj) > 0 {
+		for _, child := range utils.SortedKeys(obj) {
+			next := append(append(keypath.Path(nil), path...), keypath.Segment{Kind: keypath.KindKey, Key: child})
+			if err := d.set(next, obj[child]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, t := range tables {
+		if len(t.path) > 0 && hasPrefix(t.path, path) {
+			return fmt.Errorf("путь '%s' является таблицей и может быть заменен только объектом", target)
+		}
+	}
+
+	// Новый ключ: в ближайшей существующей таблице
+	table := 0
+	for i, t := range tables {
+		if hasPrefix(path, t.path) && len(t.path) >= len(tables[table].path) {
+			table = i
+		}
+	}
+	rest := path[len(tables[table].path):]
+	for _, seg := range rest {
+		if seg.Kind != keypath.KindKey {
+			return fmt.Errorf("путь '%s': элемент массива не существует", target)
+		}
+	}
+
+	text, err := tomlValue(value, '"')
+	if err != nil {
+		return fmt.Errorf("путь '%s': %w", target, err)
+	}
+
+	nl := l.newline()
+	t := tables[table]
+
+	// Вложенный ключ в корне оформляется новой таблицей в конце файла
+	if t.header < 0 && len(rest) > 1 {
+		data := withTrailingNewline(d.data, nl)
+		prefix := ""
+		if len(data) > 0 {
+			prefix = nl
+		}
+		line := prefix + "[" + tomlPathText(rest[:len(rest)-1]) + "]" + nl +
+			tomlPathText(rest[len(rest)-1:]) + " = " + text + nl
+		return d.commit(append(data, line...), path, value)
+	}
+
+	line := tomlPathText(rest) + " = " + text
+
+	var edited []byte
+	switch {
+	case t.last >= 0:
+		edited = splice(d.data, l.end(t.last), l.end(t.last), nl+line)
+	case t.header >= 0:
+		edited = splice(d.data, l.end(t.header), l.end(t.header), nl+line)
+	case len(tables) > 1:
+		// Корень без ключей: перед первой таблицей
+		start := l.starts[tables[1].header]
+		edited = splice(d.data, start, start, line+nl+nl)
+	default:
+		edited = append(withTrailingNewline(d.data, nl), line+nl...)
+	}
+	return d.commit(edited, path, value)
+}
+
+// hasPrefix проверяет, что путь начинается с prefix
+func hasPrefix(path, prefix keypath.Path) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+	for i, seg := range prefix {
+		if path[i] != seg {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,516 @@
+package document
+
+// yaml.go
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/keypath"
+	"gopkg.in/yaml.v3"
+)
+
+// yamlDocument редактирует YAML по позициям узлов yaml.Node:
+// скаляры и flow-значения заменяются на месте, блочные значения -
+// вместе со всеми строками блока, новые ключи добавляются после
+// последней строки родительского блока с его отступом
+type yamlDocument struct {
+	base
+}
+
+func (d *yamlDocument) Set(key string, value interface{}) error {
+	path, err := editablePath(key)
+	if err != nil {
+		return err
+	}
+	path = d.absolute(path)
+	value = keypath.Normalize(value)
+
+	edited, err := d.edit(path, value)
+	if err != nil {
+		return fmt.Errorf("путь '%s': %w", key, err)
+	}
+	return d.commit(edited, path, value)
+}
+
+// edit находит узел пути (или ближайшего существующего предка) и правит данные
+func (d *yamlDocument) edit(path keypath.Path, value interface{}) ([]byte, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(d.data, &root); err != nil {
+		return nil, err
+	}
+
+	l := newLines(d.data)
+	nl := l.newline()
+
+	// Пустой документ: дописываем блок в конец
+	if root.Kind != yaml.DocumentNode || len(root.Content) == 0 {
+		if path[0].Kind != keypath.KindKey {
+			return nil, fmt.Errorf("корень документа не является массивом")
+		}
+		text, err := yamlBlock(build(path, value), 2)
+		if err != nil {
+			return nil, err
+		}
+		return append(withTrailingNewline(d.data, nl), indentLines(text, "", nl)+nl...), nil
+	}
+
+	e := &yamlEditor{data: d.data, lines: l, nl: nl, unit: yamlIndentUnit(root.Content[0])}
+
+	node := root.Content[0]
+	var parent, keyNode *yaml.Node
+	for i, seg := range path {
+		if node.Kind == yaml.AliasNode {
+			return nil, fmt.Errorf("изменение значений через алиас не поддерживается")
+		}
+
+		child, key := yamlChild(node, seg)
+		if child != nil {
+			parent, keyNode, node = node, key, child
+			continue
+		}
+
+		rest := path[i:]
+		switch {
+		case node.Kind != yaml.MappingNode && node.Kind != yaml.SequenceNode:
+			// Скаляр (например, пустой ключ "tls:") заменяется новым объектом
+			if parent == nil {
+				return nil, fmt.Errorf("корень документа не является объектом")
+			}
+			return e.replace(parent, keyNode, node, build(rest, value))
+		case node.Style&yaml.FlowStyle != 0 || len(node.Content) == 0:
+			// flow-значение переписывается целиком
+			var current interface{}
+			if err := node.Decode(&current); err != nil {
+				return nil, err
+			}
+			holder := map[string]interface{}{"_": keypath.Normalize(current)}
+			inner := append(keypath.Path{{Kind: keypath.KindKey, Key: "_"}}, rest...)
+			if err := inner.Set(holder, value); err != nil {
+				return nil, err
+			}
+			if parent == nil {
+				return nil, fmt.Errorf("flow-стиль корня документа не поддерживается")
+			}
+			return e.replace(parent, keyNode, node, holder["_"])
+		default:
+			return e.insert(node, rest, value)
+		}
+	}
+
+	return e.replace(parent, keyNode, node, value)
+}
+
+// yamlChild возвращает дочерний узел и узел ключа для сегмента пути
+func yamlChild(node *yaml.Node, seg keypath.Segment) (*yaml.Node, *yaml.Node) {
+	switch {
+	case node.Kind == yaml.MappingNode && seg.Kind == keypath.KindKey:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == seg.Key {
+				return node.Content[i+1], node.Content[i]
+			}
+		}
+	case node.Kind == yaml.SequenceNode && seg.Kind == keypath.KindIndex:
+		if seg.Index >= 0 && seg.Index < len(node.Content) {
+			return node.Content[seg.Index], nil
+		}
+	}
+	return nil, nil
+}
+
+// yamlIndentUnit определяет шаг отступа вложенных блоков файла (по умолчанию 2)
+func yamlIndentUnit(root *yaml.Node) int {
+	unit := 0
+
+	var walk func(node *yaml.Node)
+	walk = func(node *yaml.Node) {
+		if node.Kind == yaml.MappingNode {
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				key, value := node.Content[i], node.Content[i+1]
+				block := (value.Kind == yaml.MappingNode || value.Kind == yaml.SequenceNode) &&
+					value.Style&yaml.FlowStyle == 0 && value.Line > key.Line
+				if step := value.Column - key.Column; block && step > 0 && (unit == 0 || step < unit) {
+					unit = step
+				}
+			}
+		}
+		for _, child := range node.Content {
+			walk(child)
+		}
+	}
+
+	walk(root)
+	if unit == 0 {
+		unit = 2
+	}
+	return unit
+}
+
+// yamlEditor выполняет одну правку документа
+type yamlEditor struct {
+	data  []byte
+	lines *lines
+	nl    string
+	unit  int
+}
+
+// offset переводит позицию узла (строка и столбец в символах) в смещение
+func (e *yamlEditor) offset(node *yaml.Node) int {
+	start := e.lines.starts[node.Line-1]
+	text := e.lines.text(node.Line - 1)
+	pos := 0
+	for i := 1; i < node.Column && pos < len(text); i++ {
+		_, size := utf8.DecodeRuneInString(text[pos:])
+		pos += size
+	}
+	return start + pos
+}
+
+// column возвращает столбец смещения в байтах от начала строки
+func (e *yamlEditor) column(offset int) int {
+	return offset - e.lines.starts[e.lines.line(offset)]
+}
+
+// valueStart возвращает начало значения узла после якоря и тега
+func (e *yamlEditor) valueStart(node *yaml.Node) int {
+	start := e.offset(node)
+	for start < len(e.data) && (e.data[start] == '&' || e.data[start] == '!') {
+		for start < len(e.data) && e.data[start] != ' ' && e.data[start] != '\n' {
+			start++
+		}
+		for start < len(e.data) && e.data[start] == ' ' {
+			start++
+		}
+	}
+	return start
+}
+
+// inlineEnd возвращает конец скаляра или flow-значения, начинающегося в start
+func (e *yamlEditor) inlineEnd(node *yaml.Node, start int, flow bool) int {
+	if node.Kind == yaml.MappingNode || node.Kind == yaml.SequenceNode {
+		return yamlFlowEnd(e.data, start)
+	}
+	return yamlScalarEnd(e.data, start, flow)
+}
+
+// yamlScalarEnd находит конец однострочного скаляра: кавычки закрываются,
+// простой скаляр заканчивается перед ": ", " #" или концом строки,
+// а внутри flow-значения - и перед , ] }
+func yamlScalarEnd(data []byte, start int, flow bool) int {
+	if start < len(data) {
+		switch data[start] {
+		case '"':
+			for i := start + 1; i < len(data); i++ {
+				if data[i] == '\\' {
+					i++
+				} else if data[i] == '"' {
+					return i + 1
+				}
+			}
+			return len(data)
+		case '\'':
+			for i := start + 1; i < len(data); i++ {
+				if data[i] == '\'' {
+					if i+1 < len(data) && data[i+1] == '\'' {
+						i++
+						continue
+					}
+					return i + 1
+				}
+			}
+			return len(data)
+		}
+	}
+
+	end := start
+	for end < len(data) && data[end] != '\n' {
+		c := data[end]
+		if c == ':' && (end+1 == len(data) || strings.IndexByte(" \t\r\n", data[end+1]) >= 0) {
+			break
+		}
+		if c == '#' && end > start && (data[end-1] == ' ' || data[end-1] == '\t') {
+			break
+		}
+		if flow && strings.IndexByte(",]}", c) >= 0 {
+			break
+		}
+		end++
+	}
+	for end > start && strings.IndexByte(" \t\r", data[end-1]) >= 0 {
+		end--
+	}
+	return end
+}
+
+// yamlFlowEnd находит закрывающую скобку flow-значения
+func yamlFlowEnd(data []byte, start int) int {
+	depth := 0
+	for i := start; i < len(data); i++ {
+		switch data[i] {
+		case '"', '\'':
+			i = yamlScalarEnd(data, i, true) - 1
+		case '[', '{':
+			depth++
+		case ']', '}':
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return len(data)
+}
+
+// blockEnd возвращает конец последней строки блока, начинающегося в строке first:
+// строки блока имеют отступ больше owner, а для последовательности
+// также строки "- " с отступом owner. Пустые строки и комментарии
+// после блока к нему не относятся
+func (e *yamlEditor) blockEnd(first, owner int, sequence bool) int {
+	last := first
+	for n := first + 1; n < e.lines.count(); n++ {
+		text := e.lines.text(n)
+		trimmed := strings.TrimSpace(text)
+		if trimmed == "" || trimmed[0] == '#' {
+			continue
+		}
+		ind := indent(text)
+		if ind > owner || (sequence && ind == owner && strings.HasPrefix(trimmed, "-")) {
+			last = n
+			continue
+		}
+		break
+	}
+	return e.lines.end(last)
+}
+
+// replace заменяет значение узла node с родителем parent
+func (e *yamlEditor) replace(parent, keyNode, node *yaml.Node, value interface{}) ([]byte, error) {
+	composite := isComposite(value)
+	flowParent := parent.Style&yaml.FlowStyle != 0
+	blockScalar := node.Kind == yaml.ScalarNode && node.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0
+	blockNode := (node.Kind == yaml.MappingNode || node.Kind == yaml.SequenceNode) &&
+		node.Style&yaml.FlowStyle == 0 && len(node.Content) > 0
+	inline := !blockScalar && !blockNode
+
+	if blockNode && node.Anchor != "" {
+		return nil, fmt.Errorf("изменение блока с якорем &%s не поддерживается", node.Anchor)
+	}
+
+	start := e.valueStart(node)
+
+	// Скаляр или flow-значение заменяется на месте
+	if inline && (!composite || node.Kind != yaml.ScalarNode || flowParent) {
+		end := e.inlineEnd(node, start, flowParent)
+		var text string
+		var err error
+		if composite {
+			text, err = yamlFlow(value)
+		} else {
+			text, err = yamlScalar(value, node.Style)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if start == end && parent.Kind == yaml.MappingNode && !flowParent {
+			// Пустое значение "key:"
+			text = " " + text
+		}
+		return splice(e.data, start, end, text), nil
+	}
+
+	switch parent.Kind {
+	case yaml.MappingNode:
+		keyStart := e.valueStart(keyNode)
+		owner := e.column(keyStart)
+		colon := yamlScalarEnd(e.data, keyStart, false)
+		for colon < len(e.data) && e.data[colon] != ':' {
+			colon++
+		}
+		colon++
+
+		var end int
+		if inline {
+			end = e.inlineEnd(node, start, false)
+		} else {
+			end = e.blockEnd(e.lines.line(start), owner, node.Kind == yaml.SequenceNode)
+		}
+
+		if !composite {
+			text, err := yamlScalar(value, 0)
+			if err != nil {
+				return nil, err
+			}
+			return splice(e.data, colon, end, " "+text), nil
+		}
+
+		text, err := yamlBlock(value, e.unit)
+		if err != nil {
+			return nil, err
+		}
+		prefix := strings.Repeat(" ", owner+e.unit)
+		return splice(e.data, colon, end, e.nl+indentLines(text, prefix, e.nl)), nil
+
+	case yaml.SequenceNode:
+		dash := start - 1
+		for dash > 0 && e.data[dash] != '-' {
+			dash--
+		}
+		owner := e.column(dash)
+
+		end := e.inlineEnd(node, start, false)
+		if !inline {
+			end = e.blockEnd(e.lines.line(start), owner, node.Kind == yaml.SequenceNode)
+		}
+
+		if !composite {
+			text, err := yamlScalar(value, 0)
+			if err != nil {
+				return nil, err
+			}
+			return splice(e.data, start, end, text), nil
+		}
+
+		text, err := yamlBlock(value, e.unit)
+		if err != nil {
+			return nil, err
+		}
+		prefix := strings.Repeat(" ", e.column(start))
+		return splice(e.data, start, end, strings.TrimPrefix(indentLines(text, prefix, e.nl), prefix)), nil
+	}
+
+	return nil, fmt.Errorf("неподдерживаемый узел YAML")
+}
+
+// insert добавляет отсутствующий ключ или элемент в блочный объект или массив
+func (e *yamlEditor) insert(node *yaml.Node, rest keypath.Path, value interface{}) ([]byte, error) {
+	if node.Anchor != "" {
+		return nil, fmt.Errorf("изменение блока с якорем &%s не поддерживается", node.Anchor)
+	}
+
+	start := e.offset(node)
+	col := e.column(start)
+
+	var block interface{}
+	var end int
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		if rest[0].Kind != keypath.KindKey {
+			return nil, fmt.Errorf("значение является объектом, а не массивом")
+		}
+		block = map[string]interface{}{rest[0].Key: build(rest[1:], value)}
+		end = e.blockEnd(e.lines.line(start), col-1, false)
+
+	case yaml.SequenceNode:
+		if rest[0].Kind != keypath.KindIndex {
+			return nil, fmt.Errorf("значение является массивом, а не объектом")
+		}
+		if rest[0].Index < len(node.Content) {
+			return nil, fmt.Errorf("индекс %d вне массива", rest[0].Index)
+		}
+		items := make([]interface{}, rest[0].Index-len(node.Content)+1)
+		items[len(items)-1] = build(rest[1:], value)
+		block = items
+		end = e.blockEnd(e.lines.line(start), col, true)
+	}
+
+	text, err := yamlBlock(block, e.unit)
+	if err != nil {
+		return nil, err
+	}
+	return splice(e.data, end, end, e.nl+indentLines(text, strings.Repeat(" ", col), e.nl)), nil
+}
+
+// isComposite сообщает, нужно ли записывать значение блоком
+func isComposite(value interface{}) bool {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return len(v) > 0
+	case []interface{}:
+		return len(v) > 0
+	}
+	return false
+}
+
+// yamlScalar форматирует скаляр в одну строку, сохраняя стиль кавычек исходного значения
+func yamlScalar(value interface{}, style yaml.Style) (string, error) {
+	if s, ok := value.(string); ok {
+		switch {
+		case style&yaml.DoubleQuotedStyle != 0 || strings.ContainsAny(s, "\n\r"):
+			return tomlBasicString(s), nil
+		case style&yaml.SingleQuotedStyle != 0:
+			return "'" + strings.ReplaceAll(s, "'", "''") + "'", nil
+		}
+	}
+
+	if isComposite(value) {
+		return yamlFlow(value)
+	}
+
+	data, err := yaml.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	text := strings.TrimRight(string(data), "\n")
+	if strings.Contains(text, "\n") {
+		return yamlFlow(value)
+	}
+	return text, nil
+}
+
+// yamlFlow форматирует значение в flow-стиле: {a: 1, b: [x, y]}
+func yamlFlow(value interface{}) (string, error) {
+	var node yaml.Node
+	if err := node.Encode(value); err != nil {
+		return "", err
+	}
+
+	var setFlow func(n *yaml.Node)
+	setFlow = func(n *yaml.Node) {
+		switch n.Kind {
+		case yaml.MappingNode, yaml.SequenceNode:
+			n.Style = yaml.FlowStyle
+		case yaml.ScalarNode:
+			if strings.ContainsAny(n.Value, "\n\r") {
+				n.Style = yaml.DoubleQuotedStyle
+			}
+		}
+		for _, child := range n.Content {
+			setFlow(child)
+		}
+	}
+	setFlow(&node)
+
+	data, err := yaml.Marshal(&node)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\n"), nil
+}
+
+// yamlBlock форматирует значение в блочном стиле с указанным шагом отступа
+func yamlBlock(value interface{}, unit int) (string, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(unit)
+	if err := enc.Encode(value); err != nil {
+		return "", err
+	}
+	if err := enc.Close(); err != nil {
+		return "", err
+	}
+	return strings.TrimRight(buf.String(), "\n"), nil
+}
+
+// indentLines добавляет отступ ко всем строкам текста и соединяет их переводом строки nl
+func indentLines(text, prefix, nl string) string {
+	parts := strings.Split(text, "\n")
+	for i, part := range parts {
+		if part != "" {
+			parts[i] = prefix + part
+		}
+	}
+	return strings.Join(parts, nl)
+}
@@ -0,0 +1,293 @@
+package env
+
+// overlay.go
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/keypath"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/parsers"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/utils"
+)
+
+// Overlay накладывает переменные окружения на загруженную конфигурацию
+// (12-factor: конфигурация хранится в окружении).
+// APP_DATABASE_HOST при Prefix "APP" и Separator "_" соответствует ключу database.host.
+// Ключи с подчеркиванием (server.limits.max_connections) находятся жадно:
+// сначала пробуется самое длинное совпадение с существующим ключом
+type Overlay struct {
+	// Prefix префикс переменных без разделителя, например "APP"
+	Prefix string
+	// Separator разделитель уровней вложенности в имени переменной
+	Separator string
+	// AllowNew разрешает создавать ключи, которых нет в конфигурации
+	// (только для map; каждый разделитель означает новый уровень)
+	AllowNew bool
+	// Environ источник переменных в формате "KEY=value", по умолчанию os.Environ
+	Environ func() []string
+}
+
+// Override одно примененное переопределение
+type Override struct {
+	Key      string
+	Variable string
+	Value    string
+}
+
+// Report отчет о наложении окружения
+type Report struct {
+	// Overrides переопределенные ключи в порядке имен переменных
+	Overrides []Override
+	// Unmatched переменные с префиксом, для которых ключ не найден
+	Unmatched []string
+}
+
+// Keys возвращает пути переопределенных ключей
+func (r *Report) Keys() []string {
+	keys := make([]string, len(r.Overrides))
+	for i, o := range r.Overrides {
+		keys[i] = o.Key
+	}
+	return keys
+}
+
+// NewOverlay создает Overlay с префиксом prefix и разделителем "_"
+func NewOverlay(prefix string) *Overlay {
+	return &Overlay{
+		Prefix:    prefix,
+		Separator: "_",
+		Environ:   os.Environ,
+	}
+}
+
+// LoadFile загружает файл любого поддерживаемого формата в v
+// (структуру или *map[string]interface{}) и накладывает окружение
+func (o *Overlay) LoadFile(path string, v interface{}) (*Report, error) {
+	if err := parsers.LoadFile(path, v); err != nil {
+		return nil, err
+	}
+	return o.Apply(v)
+}
+
+// Apply накладывает окружение на map[string]interface{} (или указатель на нее)
+// либо на указатель на структуру
+func (o *Overlay) Apply(v interface{}) (*Report, error) {
+	switch m := v.(type) {
+	case map[string]interface{}:
+		return o.ApplyMap(m)
+	case *map[string]interface{}:
+		if *m == nil {
+			*m = make(map[string]interface{})
+		}
+		return o.ApplyMap(*m)
+	default:
+		return o.ApplyStruct(v)
+	}
+}
+
+// ApplyMap накладывает окружение на динамическую конфигурацию.
+// Значения приводятся к типу существующего значения
+func (o *Overlay) ApplyMap(m map[string]interface{}) (*Report, error) {
+	return o.apply(func(segments []string, value string) (string, bool, error) {
+		return o.setMap(m, "", segments, value)
+	})
+}
+
+// ApplyStruct накладывает окружение на структуру по указателю.
+// Значения приводятся к типам полей
+func (o *Overlay) ApplyStruct(v interface{}) (*Report, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("ожидался указатель на структуру, получено %T", v)
+	}
+
+	return o.apply(func(segments []string, value string) (string, bool, error) {
+		return o.setStruct(rv.Elem(), "", segments, value)
+	})
+}
+
+// apply перебирает переменные с префиксом и вызывает set для каждой
+func (o *Overlay) apply(set func(segments []string, value string) (string, bool, error)) (*Report, error) {
+	report := &Report{}
+	var errs []error
+
+	for _, variable := range o.variables() {
+		name, value, _ := strings.Cut(variable, "=")
+
+		segments := o.segments(name)
+		if segments == nil {
+			continue
+		}
+
+		key, ok, err := set(segments, value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		if !ok {
+			report.Unmatched = append(report.Unmatched, name)
+			continue
+		}
+		report.Overrides = append(report.Overrides, Override{Key: key, Variable: name, Value: value})
+	}
+
+	return report, errors.Join(errs...)
+}
+
+// variables возвращает отсортированные переменные окружения
+func (o *Overlay) variables() []string {
+	environ := o.Environ
+	if environ == nil {
+		environ = os.Environ
+	}
+
+	vars := environ()
+	sort.Strings(vars)
+	return vars
+}
+
+// segments возвращает части имени переменной после префикса в нижнем регистре
+// или nil, если переменная не относится к конфигурации
+func (o *Overlay) segments(name string) []string {
+	sep := o.Separator
+	if sep == "" {
+		sep = "_"
+	}
+
+	upper := strings.ToUpper(name)
+	if o.Prefix != "" {
+		prefix := strings.ToUpper(o.Prefix) + sep
+		if !strings.HasPrefix(upper, prefix) {
+			return nil
+		}
+		upper = upper[len(prefix):]
+	}
+	if upper == "" {
+		return nil
+	}
+
+	return strings.Split(strings.ToLower(upper), sep)
+}
+
+// setMap ищет ключ для segments в map и записывает приведенное значение
+func (o *Overlay) setMap(m map[string]interface{}, prefix string, segments []string, value string) (string, bool, error) {
+	for n := len(segments); n >= 1; n-- {
+		key, exists := findKey(m, strings.Join(segments[:n], "_"))
+		if !exists {
+			continue
+		}
+		path := keypath.Join(prefix, key)
+
+		if n == len(segments) {
+			parsed, err := utils.ParseLike(value, m[key])
+			if err != nil {
+				return path, false, fmt.Errorf("ключ %s: %w", path, err)
+			}
+			m[key] = parsed
+			return path, true, nil
+		}
+
+		if nested, ok := m[key].(map[string]interface{}); ok {
+			if path, ok, err := o.setMap(nested, path, segments[n:], value); ok || err != nil {
+				return path, ok, err
+			}
+		}
+	}
+
+	if !o.AllowNew {
+		return "", false, nil
+	}
+
+	for _, segment := range segments[:len(segments)-1] {
+		prefix = keypath.Join(prefix, segment)
+		nested, ok := m[segment].(map[string]interface{})
+		if !ok {
+			nested = make(map[string]interface{})
+			m[segment] = nested
+		}
+		m = nested
+	}
+	last := segments[len(segments)-1]
+	m[last] = value
+	return keypath.Join(prefix, last), true, nil
+}
+
+// setStruct ищет поле для segments и записывает приведенное значение
+func (o *Overlay) setStruct(v reflect.Value, prefix string, segments []string, value string) (string, bool, error) {
+	t := v.Type()
+
+	for n := len(segments); n >= 1; n-- {
+		candidate := strings.Join(segments[:n], "_")
+
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name := utils.KeyName(field)
+			if !field.IsExported() || name == "-" {
+				continue
+			}
+			if normalize(name) != candidate && normalize(field.Name) != candidate {
+				continue
+			}
+
+			path := keypath.Join(prefix, name)
+			fv := v.Field(i)
+
+			if n == len(segments) {
+				if err := utils.SetFromString(fv, value); err != nil {
+					return path, false, fmt.Errorf("поле %s: %w", path, err)
+				}
+				return path, true, nil
+			}
+
+			if path, ok, err := o.descend(fv, path, segments[n:], value); ok || err != nil {
+				return path, ok, err
+			}
+		}
+	}
+
+	return "", false, nil
+}
+
+// descend продолжает поиск во вложенной структуре, указателе или map
+func (o *Overlay) descend(v reflect.Value, path string, segments []string, value string) (string, bool, error) {
+	switch v.Kind() {
+	case reflect.Struct:
+		return o.setStruct(v, path, segments, value)
+	case reflect.Ptr:
+		if v.Type().Elem().Kind() != reflect.Struct {
+			return "", false, nil
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return o.setStruct(v.Elem(), path, segments, value)
+	case reflect.Map:
+		if m, ok := v.Interface().(map[string]interface{}); ok && m != nil {
+			return o.setMap(m, path, segments, value)
+		}
+	}
+	return "", false, nil
+}
+
+// findKey ищет ключ map без учета регистра, '-' и '.'
+func findKey(m map[string]interface{}, candidate string) (string, bool) {
+	if _, exists := m[candidate]; exists {
+		return candidate, true
+	}
+	for key := range m {
+		if normalize(key) == candidate {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// normalize приводит ключ к виду сегментов имени переменной
+func normalize(key string) string {
+	return strings.NewReplacer("-", "_", ".", "_").Replace(strings.ToLower(key))
+}
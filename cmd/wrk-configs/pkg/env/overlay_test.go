@@ -0,0 +1,128 @@
+package env
+
+import (
+	"reflect"
+	"testing"
+)
+
+// environ возвращает фиксированный список переменных
+func environ(vars ...string) func() []string {
+	return func() []string { return vars }
+}
+
+func TestApplyMap(t *testing.T) {
+	config := map[string]interface{}{
+		"database": map[string]interface{}{"host": "localhost", "port": 5432},
+		"server": map[string]interface{}{
+			"limits": map[string]interface{}{"max_connections": 100},
+			"debug":  false,
+		},
+		"rate": 0.5,
+	}
+
+	o := NewOverlay("APP")
+	o.Environ = environ(
+		"APP_DATABASE_HOST=db.example.com",
+		"APP_DATABASE_PORT=6543",
+		"APP_SERVER_LIMITS_MAX_CONNECTIONS=500",
+		"APP_SERVER_DEBUG=true",
+		"APP_RATE=0.75",
+		"APP_UNKNOWN=x",
+		"OTHER_DATABASE_HOST=ignored",
+	)
+
+	report, err := o.ApplyMap(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]interface{}{
+		"database": map[string]interface{}{"host": "db.example.com", "port": 6543},
+		"server": map[string]interface{}{
+			"limits": map[string]interface{}{"max_connections": 500},
+			"debug":  true,
+		},
+		"rate": 0.75,
+	}
+	if !reflect.DeepEqual(config, want) {
+		t.Errorf("получено %#v", config)
+	}
+
+	keys := []string{"database.host", "database.port", "rate", "server.debug", "server.limits.max_connections"}
+	if got := report.Keys(); !reflect.DeepEqual(got, keys) {
+		t.Errorf("Keys = %q, ожидалось %q", got, keys)
+	}
+	if !reflect.DeepEqual(report.Unmatched, []string{"APP_UNKNOWN"}) {
+		t.Errorf("Unmatched = %q", report.Unmatched)
+	}
+}
+
+func TestApplyMapErrorsAndNew(t *testing.T) {
+	config := map[string]interface{}{"port": 80}
+	o := NewOverlay("APP")
+	o.Environ = environ("APP_PORT=abc")
+	if _, err := o.ApplyMap(config); err == nil {
+		t.Error("значение не приводится к типу: ожидалась ошибка")
+	}
+	if config["port"] != 80 {
+		t.Errorf("значение изменено при ошибке: %v", config["port"])
+	}
+
+	config = map[string]interface{}{}
+	o = NewOverlay("APP")
+	o.AllowNew = true
+	o.Environ = environ("APP_CACHE_TTL=5")
+	if _, err := o.ApplyMap(config); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{"cache": map[string]interface{}{"ttl": "5"}}
+	if !reflect.DeepEqual(config, want) {
+		t.Errorf("AllowNew: получено %v", config)
+	}
+}
+
+func TestApplyStruct(t *testing.T) {
+	type database struct {
+		Host    string `json:"host"`
+		Port    int    `json:"port"`
+		MaxConn int    `yaml:"max_conn"`
+	}
+	var cfg struct {
+		Database database  `json:"database"`
+		Cache    *database `json:"cache"`
+		Debug    bool
+		Tags     []string `json:"tags"`
+	}
+
+	o := NewOverlay("")
+	o.Environ = environ(
+		"DATABASE_HOST=db",
+		"DATABASE_PORT=6543",
+		"DATABASE_MAX_CONN=20",
+		"CACHE_PORT=6379",
+		"DEBUG=1",
+		"TAGS=a,b",
+		"HOME=/root",
+	)
+	report, err := o.Apply(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Database != (database{Host: "db", Port: 6543, MaxConn: 20}) {
+		t.Errorf("Database = %+v", cfg.Database)
+	}
+	if cfg.Cache == nil || cfg.Cache.Port != 6379 {
+		t.Errorf("Cache = %+v", cfg.Cache)
+	}
+	if !cfg.Debug || !reflect.DeepEqual(cfg.Tags, []string{"a", "b"}) {
+		t.Errorf("Debug = %v, Tags = %q", cfg.Debug, cfg.Tags)
+	}
+	if !reflect.DeepEqual(report.Unmatched, []string{"HOME"}) {
+		t.Errorf("Unmatched = %q", report.Unmatched)
+	}
+
+	if _, err := o.Apply(cfg); err == nil {
+		t.Error("структура не по указателю: ожидалась ошибка")
+	}
+}
@@ -0,0 +1,208 @@
+package generators
+
+// gofile.go
+
+import (
+	"fmt"
+	"go/format"
+	"go/token"
+	"sort"
+	"strings"
+)
+
+// parsersImport пакет с реестром парсеров, через который сгенерированный
+// Load читает файлы любого формата
+const parsersImport = "github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/parsers"
+
+// GoFileOptions параметры GenerateGoFile
+type GoFileOptions struct {
+	// Package имя пакета сгенерированного файла; по умолчанию "config"
+	Package string
+	// Root структура, которую возвращает Load; по умолчанию первая корневая
+	Root string
+	// Source описание источника для заголовка "Code generated"
+	Source string
+	// Validate добавляет методы Validate, проверяющие строки, непустые во всех образцах
+	Validate bool
+	// Defaults добавляет методы Default со значениями из образцов
+	Defaults bool
+}
+
+// GenerateGoFile возвращает отформатированный gofmt файл Go: package,
+// импорты, структуры и функцию Load(path), читающую конфигурацию любого
+// зарегистрированного формата через parsers.LoadFile. Load вызывает
+// Default и Validate, если они генерируются
+func (g *StructGenerator) GenerateGoFile(opts GoFileOptions) ([]byte, error) {
+	if len(g.rootOrder) == 0 {
+		return nil, fmt.Errorf("нет проанализированных конфигураций")
+	}
+
+	pkg := opts.Package
+	if pkg == "" {
+		pkg = "config"
+	}
+	if !token.IsIdentifier(pkg) || token.IsKeyword(pkg) {
+		return nil, fmt.Errorf("недопустимое имя пакета: %q", pkg)
+	}
+
+	root := opts.Root
+	if root == "" {
+		root = g.rootOrder[0]
+	}
+	if _, ok := g.roots[root]; !ok {
+		return nil, fmt.Errorf("корневая структура %s не найдена", root)
+	}
+
+	g.build()
+	names := make([]string, 0, len(g.structs))
+	for name := range g.structs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var body strings.Builder
+	for _, name := range names {
+		g.writeStruct(&body, g.structs[name])
+	}
+	if opts.Defaults {
+		for _, name := range names {
+			g.writeDefault(&body, g.structs[name])
+		}
+	}
+	usesFmt := false
+	if opts.Validate {
+		for _, name := range names {
+			if g.writeValidate(&body, g.structs[name]) {
+				usesFmt = true
+			}
+		}
+	}
+	writeLoad(&body, root, opts)
+
+	imports := make([]string, 0, len(g.imports)+1)
+	for path := range g.imports {
+		imports = append(imports, path)
+	}
+	if usesFmt {
+		imports = append(imports, "fmt")
+	}
+	sort.Strings(imports)
+
+	var file strings.Builder
+	source := ""
+	if opts.Source != "" {
+		source = " from " + opts.Source
+	}
+	fmt.Fprintf(&file, "// Code generated by config-struct%s; DO NOT EDIT.\n\n", source)
+	fmt.Fprintf(&file, "package %s\n\nimport (\n", pkg)
+	// Стандартная библиотека отделяется от parsers пустой строкой, как в goimports
+	for _, path := range imports {
+		fmt.Fprintf(&file, "\t%q\n", path)
+	}
+	if len(imports) > 0 {
+		file.WriteString("\n")
+	}
+	fmt.Fprintf(&file, "\t%q\n)\n\n", parsersImport)
+	file.WriteString(body.String())
+
+	formatted, err := format.Source([]byte(file.String()))
+	if err != nil {
+		return nil, fmt.Errorf("сгенерированный код не форматируется: %w", err)
+	}
+	return formatted, nil
+}
+
+// writeStruct выводит объявление структуры
+func (g *StructGenerator) writeStruct(b *strings.Builder, info *StructInfo) {
+	fmt.Fprintf(b, "// %s сгенерирована по образцу конфигурации\n", info.Name)
+	fmt.Fprintf(b, "type %s struct {\n", info.Name)
+	for _, field := range info.Fields {
+		fmt.Fprintf(b, "\t%s %s %s\n", field.Name, field.Type, g.fieldTag(field))
+	}
+	b.WriteString("}\n\n")
+}
+
+// writeDefault выводит метод Default: незаданные обязательные поля
+// получают значения из образца, вложенные структуры заполняются рекурсивно
+func (g *StructGenerator) writeDefault(b *strings.Builder, info *StructInfo) {
+	fmt.Fprintf(b, "// Default заполняет незаданные поля %s значениями из образца\n", info.Name)
+	fmt.Fprintf(b, "func (c *%s) Default() {\n", info.Name)
+	for _, field := range info.Fields {
+		if field.Default != "" {
+			fmt.Fprintf(b, "\tif c.%s == %s {\n\t\tc.%s = %s\n\t}\n",
+				field.Name, zeroValue(field.Type), field.Name, field.Default)
+		}
+		g.writeNested(b, field, func(target, _ string) string {
+			return fmt.Sprintf("c.%s.Default()", target)
+		})
+	}
+	b.WriteString("}\n\n")
+}
+
+// writeValidate выводит метод Validate: строки, непустые во всех образцах,
+// не должны быть пустыми, вложенные структуры проверяются рекурсивно, а ошибка
+// содержит путь ключей. Возвращает true, если метод использует fmt
+func (g *StructGenerator) writeValidate(b *strings.Builder, info *StructInfo) bool {
+	var checks strings.Builder
+	for _, field := range info.Fields {
+		if field.Required {
+			fmt.Fprintf(&checks, "\tif c.%s == \"\" {\n\t\treturn fmt.Errorf(\"%%s: обязательное поле не задано\", %q)\n\t}\n",
+				field.Name, field.Key)
+		}
+		g.writeNested(&checks, field, func(target, errArgs string) string {
+			return fmt.Sprintf("if err := c.%s.Validate(); err != nil {\n\t\treturn fmt.Errorf(%s, err)\n\t}", target, errArgs)
+		})
+	}
+
+	fmt.Fprintf(b, "// Validate проверяет обязательные поля %s\n", info.Name)
+	fmt.Fprintf(b, "func (c *%s) Validate() error {\n", info.Name)
+	b.WriteString(checks.String())
+	b.WriteString("\treturn nil\n}\n\n")
+	return checks.Len() > 0
+}
+
+// writeNested выводит вызов метода для поля-структуры, указателя
+// на структуру или среза структур. call получает выражение поля
+// и аргументы fmt.Errorf, добавляющие к ошибке путь ключа
+func (g *StructGenerator) writeNested(b *strings.Builder, field StructField, call func(target, errArgs string) string) {
+	switch {
+	case g.isStruct(field.Type):
+		fmt.Fprintf(b, "\t%s\n", call(field.Name, fmt.Sprintf(`"%%s: %%w", %q`, field.Key)))
+	case strings.HasPrefix(field.Type, "*") && g.isStruct(field.Type[1:]):
+		fmt.Fprintf(b, "\tif c.%s != nil {\n\t%s\n\t}\n",
+			field.Name, call(field.Name, fmt.Sprintf(`"%%s: %%w", %q`, field.Key)))
+	case strings.HasPrefix(field.Type, "[]") && g.isStruct(field.Type[2:]):
+		fmt.Fprintf(b, "\tfor i := range c.%s {\n\t%s\n\t}\n",
+			field.Name, call(field.Name+"[i]", fmt.Sprintf(`"%%s[%%d]: %%w", %q, i`, field.Key)))
+	}
+}
+
+// isStruct сообщает, что typeName - сгенерированная структура
+func (g *StructGenerator) isStruct(typeName string) bool {
+	_, ok := g.structs[typeName]
+	return ok
+}
+
+// zeroValue возвращает нулевое значение скалярного типа
+func zeroValue(typeName string) string {
+	if typeName == "string" {
+		return `""`
+	}
+	return "0"
+}
+
+// writeLoad выводит функцию Load для корневой структуры
+func writeLoad(b *strings.Builder, root string, opts GoFileOptions) {
+	b.WriteString("// Load читает конфигурацию JSON, YAML, INI или TOML;\n")
+	b.WriteString("// формат определяется по расширению или содержимому файла\n")
+	fmt.Fprintf(b, "func Load(path string) (*%s, error) {\n", root)
+	fmt.Fprintf(b, "\tvar config %s\n", root)
+	b.WriteString("\tif err := parsers.LoadFile(path, &config); err != nil {\n\t\treturn nil, err\n\t}\n")
+	if opts.Defaults {
+		b.WriteString("\tconfig.Default()\n")
+	}
+	if opts.Validate {
+		b.WriteString("\tif err := config.Validate(); err != nil {\n\t\treturn nil, err\n\t}\n")
+	}
+	b.WriteString("\treturn &config, nil\n}\n")
+}
@@ -0,0 +1,200 @@
+package generators
+
+// ini.go
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/types"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/utils"
+	"gopkg.in/ini.v1"
+)
+
+// INIGenerator сериализует конфигурации в INI.
+// Структуры отображаются через ini-теги (ini.ReflectFrom).
+// Для динамических map скалярные ключи корня попадают в секцию по умолчанию,
+// вложенные объекты - в секции, а более глубокие объекты - в секции
+// с составными именами вида [server.limits], как их читает INIParser.ParseDynamic.
+// Массивы скаляров записываются через Delimiter, прочие массивы - строкой JSON
+type INIGenerator struct {
+	// Indent строка отступа ключей внутри секций
+	Indent string
+	// Delimiter разделитель элементов массивов
+	Delimiter string
+}
+
+func init() {
+	Register(NewINIGenerator())
+}
+
+func NewINIGenerator() *INIGenerator {
+	return &INIGenerator{Delimiter: ", "}
+}
+
+func (g *INIGenerator) Generate(v interface{}) ([]byte, error) {
+	cfg := ini.Empty()
+
+	if m, ok := asMap(v); ok {
+		if err := g.fillSection(cfg, "", m); err != nil {
+			return nil, err
+		}
+	} else {
+		if err := cfg.ReflectFrom(v); err != nil {
+			return nil, err
+		}
+		if err := reflectText(cfg, cfg.Section(ini.DefaultSection), reflect.ValueOf(v)); err != nil {
+			return nil, err
+		}
+	}
+
+	var buf bytes.Buffer
+	if _, err := cfg.WriteToIndent(&buf, g.Indent); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (g *INIGenerator) Format() types.ConfigFormat {
+	return types.FormatINI
+}
+
+// fillSection записывает скалярные ключи m в секцию name,
+// а вложенные объекты - в дочерние секции
+func (g *INIGenerator) fillSection(cfg *ini.File, name string, m map[string]interface{}) error {
+	section, err := cfg.NewSection(sectionName(name))
+	if err != nil {
+		return err
+	}
+
+	var nested []string
+	for _, key := range utils.SortedKeys(m) {
+		if _, ok := m[key].(map[string]interface{}); ok {
+			nested = append(nested, key)
+			continue
+		}
+
+		value, err := g.formatValue(m[key])
+		if err != nil {
+			return fmt.Errorf("ключ %s: %w", joinKey(name, key), err)
+		}
+		if _, err := section.NewKey(key, value); err != nil {
+			return err
+		}
+	}
+
+	for _, key := range nested {
+		if err := g.fillSection(cfg, joinKey(name, key), m[key].(map[string]interface{})); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// reflectText перезаписывает ключи строковых полей с MarshalText:
+// ini.ReflectFrom записывает их как обычные строки, и secrets.Secret
+// попал бы в файл открытым текстом
+func reflectText(cfg *ini.File, section *ini.Section, v reflect.Value) error {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("ini"), ",")
+		if !field.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		fv := v.Field(i)
+		if marshaler, ok := fv.Interface().(encoding.TextMarshaler); ok && fv.Kind() == reflect.String {
+			if !section.HasKey(name) {
+				continue
+			}
+			text, err := marshaler.MarshalText()
+			if err != nil {
+				return fmt.Errorf("поле %s: %w", field.Name, err)
+			}
+			section.Key(name).SetValue(string(text))
+			continue
+		}
+
+		if nested, err := cfg.GetSection(name); err == nil {
+			if err := reflectText(cfg, nested, fv); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// formatValue преобразует значение в строку INI
+func (g *INIGenerator) formatValue(value interface{}) (string, error) {
+	if arr, ok := value.([]interface{}); ok {
+		items := make([]string, len(arr))
+		for i, item := range arr {
+			switch item.(type) {
+			case map[string]interface{}, []interface{}:
+				data, err := json.Marshal(arr)
+				return string(data), err
+			}
+			items[i] = formatScalar(item)
+		}
+		return strings.Join(items, g.Delimiter), nil
+	}
+	return formatScalar(value), nil
+}
+
+// formatScalar преобразует скалярное значение в строку
+func formatScalar(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case encoding.TextMarshaler:
+		if text, err := v.MarshalText(); err == nil {
+			return string(text)
+		}
+	}
+	return fmt.Sprint(value)
+}
+
+// sectionName возвращает имя секции ini для пути
+func sectionName(name string) string {
+	if name == "" {
+		return ini.DefaultSection
+	}
+	return name
+}
+
+// joinKey соединяет части пути точкой
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
@@ -0,0 +1,33 @@
+package generators
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/secrets"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/types"
+)
+
+func TestINIGeneratorHidesSecrets(t *testing.T) {
+	var v struct {
+		Name     string `ini:"name"`
+		Database struct {
+			Password secrets.Secret `ini:"password"`
+		} `ini:"database"`
+	}
+	v.Name = "app"
+	v.Database.Password = "hunter2"
+
+	for _, g := range []types.Generator{NewINIGenerator(), NewTOMLGenerator()} {
+		data, err := g.Generate(&v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(data), "hunter2") {
+			t.Errorf("%s: секрет записан открытым текстом:\n%s", g.Format(), data)
+		}
+		if !strings.Contains(string(data), "app") {
+			t.Errorf("%s: нет обычного поля:\n%s", g.Format(), data)
+		}
+	}
+}
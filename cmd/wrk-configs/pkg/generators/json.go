@@ -0,0 +1,44 @@
+package generators
+
+// json.go
+
+import (
+	"encoding/json"
+
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/types"
+)
+
+// JSONGenerator сериализует конфигурации в JSON.
+// Ключи map выводятся в отсортированном порядке, поля структур - в порядке объявления
+type JSONGenerator struct {
+	// Indent строка отступа; пустая строка дает компактный вывод
+	Indent string
+}
+
+func init() {
+	Register(NewJSONGenerator())
+}
+
+func NewJSONGenerator() *JSONGenerator {
+	return &JSONGenerator{Indent: "  "}
+}
+
+func (g *JSONGenerator) Generate(v interface{}) ([]byte, error) {
+	var (
+		data []byte
+		err  error
+	)
+	if g.Indent == "" {
+		data, err = json.Marshal(v)
+	} else {
+		data, err = json.MarshalIndent(v, "", g.Indent)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+func (g *JSONGenerator) Format() types.ConfigFormat {
+	return types.FormatJSON
+}
@@ -0,0 +1,112 @@
+package generators
+
+// lossy.go
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/types"
+)
+
+// Loss описывает потерю данных или структуры при сериализации в формат
+type Loss struct {
+	Path   string
+	Reason string
+}
+
+func (l Loss) String() string {
+	return fmt.Sprintf("%s: %s", l.Path, l.Reason)
+}
+
+// CheckLossy сообщает, какие значения динамической конфигурации
+// не могут быть точно представлены в формате format.
+// Результат отсортирован по пути
+func CheckLossy(value map[string]interface{}, format types.ConfigFormat) []Loss {
+	var losses []Loss
+
+	switch format {
+	case types.FormatINI:
+		checkINI(value, "", 0, &losses)
+	case types.FormatTOML:
+		checkTOML(value, "", &losses)
+	}
+
+	sort.Slice(losses, func(i, j int) bool { return losses[i].Path < losses[j].Path })
+	return losses
+}
+
+// checkINI проверяет значения для INI: корень и один уровень секций
+// представимы точно, все остальное - с потерями. Числа, bool и даты
+// записываются строками и при чтении теряют тип
+func checkINI(m map[string]interface{}, prefix string, depth int, losses *[]Loss) {
+	for key, value := range m {
+		path := joinKey(prefix, key)
+
+		switch v := value.(type) {
+		case map[string]interface{}:
+			if depth >= 1 {
+				*losses = append(*losses, Loss{path, "объект глубже секции INI будет записан как секция [" + path + "]"})
+			}
+			checkINI(v, path, depth+1, losses)
+		case []interface{}:
+			if hasComplexItems(v) {
+				*losses = append(*losses, Loss{path, "массив с объектами или массивами будет записан строкой JSON"})
+			} else {
+				*losses = append(*losses, Loss{path, "массив будет записан строкой через разделитель"})
+			}
+		case nil:
+			*losses = append(*losses, Loss{path, "null будет записан пустой строкой"})
+		case string:
+		default:
+			// Самая частая потеря: в INI нет типов, значение прочитается строкой
+			*losses = append(*losses, Loss{path, scalarName(v) + " будет записано строкой без типа"})
+		}
+	}
+}
+
+// scalarName называет вид скалярного значения для сообщений о потерях
+func scalarName(value interface{}) string {
+	switch value.(type) {
+	case bool:
+		return "логическое значение"
+	case time.Time:
+		return "дата и время"
+	}
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "число"
+	}
+	return "значение"
+}
+
+// checkTOML проверяет значения для TOML: в TOML нет null
+func checkTOML(value interface{}, path string, losses *[]Loss) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			checkTOML(item, joinKey(path, key), losses)
+		}
+	case []interface{}:
+		for i, item := range v {
+			checkTOML(item, fmt.Sprintf("%s[%d]", path, i), losses)
+		}
+	case nil:
+		*losses = append(*losses, Loss{path, "null не поддерживается в TOML, значение будет пропущено"})
+	}
+}
+
+// hasComplexItems проверяет, есть ли в массиве объекты или вложенные массивы
+func hasComplexItems(arr []interface{}) bool {
+	for _, item := range arr {
+		switch item.(type) {
+		case map[string]interface{}, []interface{}:
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package generators
+
+// naming.go
+
+import (
+	"go/token"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// commonInitialisms аббревиатуры, которые в Go пишутся целиком заглавными
+// (список golint): user_id - UserID, base_url - BaseURL, http_port - HTTPPort
+var commonInitialisms = map[string]bool{
+	"ACL": true, "API": true, "ASCII": true, "CPU": true, "CSS": true,
+	"DNS": true, "EOF": true, "GUID": true, "HTML": true, "HTTP": true,
+	"HTTPS": true, "ID": true, "IP": true, "JSON": true, "LHS": true,
+	"QPS": true, "RAM": true, "RHS": true, "RPC": true, "SLA": true,
+	"SMTP": true, "SQL": true, "SSH": true, "TCP": true, "TLS": true,
+	"TTL": true, "UDP": true, "UI": true, "UID": true, "UUID": true,
+	"URI": true, "URL": true, "UTF8": true, "VM": true, "XML": true,
+	"XMPP": true, "XSRF": true, "XSS": true,
+}
+
+// identifierPrefix добавляется к именам, которые не начинаются
+// с заглавной буквы: ключам с цифры ("2fa" - Field2fa) и ключам
+// из букв без регистра
+const identifierPrefix = "Field"
+
+// exportedName преобразует ключ конфигурации в экспортируемый Go-идентификатор:
+// слова разделяются по не буквенно-цифровым символам и границам camelCase,
+// аббревиатуры пишутся заглавными. Экспортируемое имя не может совпасть
+// с ключевым словом Go, но результат все равно проверяется go/token
+func exportedName(key string) string {
+	var result strings.Builder
+	for _, word := range splitWords(key) {
+		if upper := strings.ToUpper(word); commonInitialisms[upper] {
+			result.WriteString(upper)
+			continue
+		}
+		first, size := utf8.DecodeRuneInString(word)
+		result.WriteRune(unicode.ToUpper(first))
+		result.WriteString(strings.ToLower(word[size:]))
+	}
+
+	name := result.String()
+	if name == "" {
+		return identifierPrefix
+	}
+	if !token.IsExported(name) || !token.IsIdentifier(name) || token.IsKeyword(name) {
+		name = identifierPrefix + name
+	}
+	return name
+}
+
+// splitWords разбивает ключ на слова: "maxConns" - max, Conns;
+// "HTTPServer" - HTTP, Server; "db-pool_size" - db, pool, size
+func splitWords(s string) []string {
+	var words []string
+	for _, part := range strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		runes := []rune(part)
+		start := 0
+		for i := 1; i < len(runes); i++ {
+			prev, cur := runes[i-1], runes[i]
+			lowerToUpper := unicode.IsLower(prev) && unicode.IsUpper(cur)
+			// Конец аббревиатуры: последняя заглавная перед строчной начинает слово
+			acronymEnd := unicode.IsUpper(prev) && unicode.IsUpper(cur) &&
+				i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if lowerToUpper || acronymEnd {
+				words = append(words, string(runes[start:i]))
+				start = i
+			}
+		}
+		words = append(words, string(runes[start:]))
+	}
+	return words
+}
+
+// uniqueName возвращает name, если оно свободно, иначе name с первым
+// свободным числовым суффиксом (MaxConns2)
+func uniqueName(name string, taken func(string) bool) string {
+	if !taken(name) {
+		return name
+	}
+	for i := 2; ; i++ {
+		if candidate := name + strconv.Itoa(i); !taken(candidate) {
+			return candidate
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package generators
+
+// registry.go
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/types"
+)
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[types.ConfigFormat]types.Generator)
+)
+
+// Register регистрирует генератор для его формата.
+// Предназначен для вызова из init(); повторная регистрация формата
+// или пустой формат приводят к панике, как и parsers.Register
+func Register(g types.Generator) {
+	if g == nil {
+		panic("generators: Register с nil генератором")
+	}
+
+	format := g.Format()
+	if format == "" {
+		panic("generators: Register с пустым форматом")
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, exists := registry[format]; exists {
+		panic(fmt.Sprintf("generators: генератор для формата %s уже зарегистрирован", format))
+	}
+	registry[format] = g
+}
+
+// Get возвращает зарегистрированный генератор для формата
+func Get(format types.ConfigFormat) (types.Generator, error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	g, exists := registry[format]
+	if !exists {
+		return nil, &types.UnsupportedFormatError{Format: format}
+	}
+	return g, nil
+}
+
+// Formats возвращает отсортированный список зарегистрированных форматов
+func Formats() []types.ConfigFormat {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	formats := make([]types.ConfigFormat, 0, len(registry))
+	for format := range registry {
+		formats = append(formats, format)
+	}
+	sort.Slice(formats, func(i, j int) bool { return formats[i] < formats[j] })
+	return formats
+}
+
+// Generate сериализует v в указанный формат зарегистрированным генератором
+func Generate(format types.ConfigFormat, v interface{}) ([]byte, error) {
+	g, err := Get(format)
+	if err != nil {
+		return nil, err
+	}
+	return g.Generate(v)
+}
@@ -0,0 +1,257 @@
+package generators
+
+// shape.go
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// shapeKind вид значения, выведенный StructGenerator
+type shapeKind int
+
+const (
+	kindNull shapeKind = iota
+	kindBool
+	kindInt
+	kindFloat
+	kindString
+	kindTime
+	kindArray
+	kindObject
+	// kindAny несовместимые типы - interface{}
+	kindAny
+)
+
+// shape обобщенный тип всех значений, встреченных в одном месте конфигурации:
+// во всех элементах массива и во всех образцах
+type shape struct {
+	kind shapeKind
+	// nullable значение хотя бы раз было null
+	nullable bool
+	// empty строка хотя бы раз была пустой
+	empty bool
+	// elem тип элементов массива; nil, если все массивы были пустыми
+	elem *shape
+	// fields поля объекта; samples - сколько объектов слито в shape
+	fields  map[string]*fieldShape
+	samples int
+	// sample первое встреченное скалярное значение - значение по умолчанию
+	sample interface{}
+}
+
+// fieldShape поле объекта и число объектов, в которых оно встретилось
+type fieldShape struct {
+	shape *shape
+	count int
+}
+
+// optional сообщает, что поле есть не во всех объектах или бывает null
+func (f *fieldShape) optional(parent *shape) bool {
+	return f.count < parent.samples || f.shape.nullable || f.shape.kind == kindNull
+}
+
+// analyzeValue анализирует значение и определяет его shape.
+// typedStrings включает вывод чисел и bool из строк (INI)
+func analyzeValue(value interface{}, typedStrings bool) *shape {
+	switch v := value.(type) {
+	case nil:
+		return &shape{kind: kindNull}
+	case bool:
+		return &shape{kind: kindBool, sample: v}
+	case string:
+		if typedStrings {
+			return &shape{kind: stringKind(v), sample: v, empty: v == ""}
+		}
+		return &shape{kind: kindString, sample: v, empty: v == ""}
+	case time.Time:
+		return &shape{kind: kindTime, sample: v}
+	case []interface{}:
+		// Тип элементов объединяется по всем элементам, а не по первому
+		s := &shape{kind: kindArray}
+		for _, item := range v {
+			s.elem = mergeShapes(s.elem, analyzeValue(item, typedStrings))
+		}
+		return s
+	case map[string]interface{}:
+		return analyzeObject(v, typedStrings)
+	}
+
+	return &shape{kind: numberKind(value), sample: value}
+}
+
+// analyzeObject анализирует объект
+func analyzeObject(obj map[string]interface{}, typedStrings bool) *shape {
+	s := &shape{kind: kindObject, fields: make(map[string]*fieldShape, len(obj)), samples: 1}
+	for key, value := range obj {
+		s.fields[key] = &fieldShape{shape: analyzeValue(value, typedStrings), count: 1}
+	}
+	return s
+}
+
+// mergeShapes объединяет два shape: null делает значение необязательным,
+// int расширяется до float, объекты и массивы сливаются рекурсивно,
+// остальные несовпадения дают interface{}. Аргументы могут изменяться
+func mergeShapes(a, b *shape) *shape {
+	switch {
+	case a == nil:
+		return b
+	case b == nil:
+		return a
+	case b.kind == kindNull:
+		a.nullable = true
+		return a
+	case a.kind == kindNull:
+		b.nullable = true
+		return b
+	}
+
+	nullable := a.nullable || b.nullable
+	empty := a.empty || b.empty
+	if a.sample == nil {
+		a.sample = b.sample
+	}
+
+	switch {
+	case a.kind == b.kind:
+		switch a.kind {
+		case kindArray:
+			a.elem = mergeShapes(a.elem, b.elem)
+		case kindObject:
+			for key, field := range b.fields {
+				if existing, ok := a.fields[key]; ok {
+					existing.shape = mergeShapes(existing.shape, field.shape)
+					existing.count += field.count
+				} else {
+					a.fields[key] = field
+				}
+			}
+			a.samples += b.samples
+		}
+	case isNumberKind(a.kind) && isNumberKind(b.kind):
+		a.kind = kindFloat
+	case isTextKind(a.kind) && isTextKind(b.kind):
+		// Дата TOML и та же дата строкой JSON
+		a.kind = kindString
+	default:
+		a = &shape{kind: kindAny}
+	}
+
+	a.nullable = nullable
+	a.empty = empty
+	return a
+}
+
+func isNumberKind(kind shapeKind) bool {
+	return kind == kindInt || kind == kindFloat
+}
+
+func isTextKind(kind shapeKind) bool {
+	return kind == kindString || kind == kindTime
+}
+
+// numberKind возвращает kindInt для целых чисел любого формата
+// (в том числе целых float64 из JSON) и kindFloat для дробных
+func numberKind(value interface{}) shapeKind {
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return kindInt
+	case reflect.Float32, reflect.Float64:
+		if isIntegral(rv.Float()) {
+			return kindInt
+		}
+		return kindFloat
+	}
+
+	// Локальные дата и время TOML и прочие значения, записываемые строкой
+	if _, ok := value.(fmt.Stringer); ok {
+		return kindString
+	}
+	return kindAny
+}
+
+// stringKind определяет тип строкового значения INI по его виду
+func stringKind(s string) shapeKind {
+	if _, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return kindInt
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return kindFloat
+	}
+	if s == "true" || s == "false" {
+		return kindBool
+	}
+	return kindString
+}
+
+// goLiteral возвращает литерал Go для значения из образца или "",
+// если значение по умолчанию не нужно: нулевые значения, bool (false
+// неотличимо от незаданного), дата и время, массивы и объекты
+func goLiteral(s *shape) string {
+	if s == nil || s.nullable {
+		return ""
+	}
+
+	switch s.kind {
+	case kindString:
+		if str, ok := s.sample.(string); ok && str != "" {
+			return strconv.Quote(str)
+		}
+	case kindInt:
+		if i, ok := sampleInt(s.sample); ok && i != 0 {
+			return strconv.FormatInt(i, 10)
+		}
+	case kindFloat:
+		if f, ok := sampleFloat(s.sample); ok && f != 0 && !math.IsInf(f, 0) && !math.IsNaN(f) {
+			return strconv.FormatFloat(f, 'g', -1, 64)
+		}
+	}
+	return ""
+}
+
+// sampleInt приводит целое значение образца любого формата к int64
+func sampleInt(value interface{}) (int64, bool) {
+	if str, ok := value.(string); ok {
+		i, err := strconv.ParseInt(str, 10, 64)
+		return i, err == nil
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if u := rv.Uint(); u <= math.MaxInt64 {
+			return int64(u), true
+		}
+	case reflect.Float32, reflect.Float64:
+		if f := rv.Float(); isIntegral(f) {
+			return int64(f), true
+		}
+	}
+	return 0, false
+}
+
+// sampleFloat приводит числовое значение образца любого формата к float64
+func sampleFloat(value interface{}) (float64, bool) {
+	if str, ok := value.(string); ok {
+		f, err := strconv.ParseFloat(str, 64)
+		return f, err == nil
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+	return 0, false
+}
@@ -0,0 +1,249 @@
+package generators
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/types"
+)
+
+func TestAnalyzeValueKinds(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		typed bool
+		want  shapeKind
+	}{
+		{"null", nil, false, kindNull},
+		{"bool", true, false, kindBool},
+		{"строка", "x", false, kindString},
+		{"целое float64 JSON", 8080.0, false, kindInt},
+		{"дробное float64", 0.5, false, kindFloat},
+		{"int YAML", 8080, false, kindInt},
+		{"int64 TOML", int64(8080), false, kindInt},
+		{"дата TOML", time.Now(), false, kindTime},
+		{"строка INI с целым", "8080", true, kindInt},
+		{"строка INI с дробным", "0.5", true, kindFloat},
+		{"строка INI с bool", "true", true, kindBool},
+		{"строка без вывода типов", "8080", false, kindString},
+		{"массив", []interface{}{1}, false, kindArray},
+		{"объект", map[string]interface{}{}, false, kindObject},
+	}
+	for _, tt := range tests {
+		if got := analyzeValue(tt.value, tt.typed).kind; got != tt.want {
+			t.Errorf("%s: kind = %d, ожидалось %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestArrayElementUnification(t *testing.T) {
+	tests := []struct {
+		name     string
+		array    string
+		kind     shapeKind
+		nullable bool
+	}{
+		{"целые", `[1, 2]`, kindInt, false},
+		{"целые и дробные", `[1, 2.5]`, kindFloat, false},
+		{"строки", `["a", "b"]`, kindString, false},
+		{"строка и число", `["a", 1]`, kindAny, false},
+		{"null делает элемент необязательным", `[1, null]`, kindInt, true},
+		{"только null", `[null]`, kindNull, false},
+		{"объекты", `[{"a": 1}, {"b": "x"}]`, kindObject, false},
+		{"объект и число", `[{"a": 1}, 1]`, kindAny, false},
+		{"вложенные массивы", `[[1], [2.5]]`, kindArray, false},
+	}
+	for _, tt := range tests {
+		s := analyzeValue(decodeJSON(t, tt.array), false)
+		if s.elem == nil {
+			t.Errorf("%s: нет типа элементов", tt.name)
+			continue
+		}
+		if s.elem.kind != tt.kind || s.elem.nullable != tt.nullable {
+			t.Errorf("%s: kind = %d, nullable = %v, ожидалось %d, %v",
+				tt.name, s.elem.kind, s.elem.nullable, tt.kind, tt.nullable)
+		}
+	}
+
+	if s := analyzeValue([]interface{}{}, false); s.elem != nil {
+		t.Errorf("пустой массив: elem = %+v, ожидалось nil", s.elem)
+	}
+}
+
+func TestObjectFieldsOptional(t *testing.T) {
+	s := analyzeValue(decodeJSON(t, `[
+		{"name": "a", "port": 80, "tls": {"cert": "x"}},
+		{"name": "b", "port": 8.5, "weight": 2},
+		{"name": "c", "port": 1, "tls": null}
+	]`), false).elem
+
+	tests := []struct {
+		key      string
+		kind     shapeKind
+		optional bool
+	}{
+		{"name", kindString, false},
+		{"port", kindFloat, false},
+		{"tls", kindObject, true},
+		{"weight", kindInt, true},
+	}
+	if s.samples != 3 {
+		t.Errorf("samples = %d, ожидалось 3", s.samples)
+	}
+	for _, tt := range tests {
+		field, ok := s.fields[tt.key]
+		if !ok {
+			t.Errorf("%s: поле не найдено", tt.key)
+			continue
+		}
+		if field.shape.kind != tt.kind || field.optional(s) != tt.optional {
+			t.Errorf("%s: kind = %d, optional = %v, ожидалось %d, %v",
+				tt.key, field.shape.kind, field.optional(s), tt.kind, tt.optional)
+		}
+	}
+}
+
+func TestMergeShapesNested(t *testing.T) {
+	a := analyzeValue(decodeJSON(t, `{"db": {"port": 1, "hosts": ["a"]}}`), false)
+	b := analyzeValue(decodeJSON(t, `{"db": {"port": 1.5, "hosts": [], "user": "x"}}`), false)
+	s := mergeShapes(a, b)
+
+	db := s.fields["db"]
+	if db.optional(s) {
+		t.Error("db есть в обоих образцах и не должен быть необязательным")
+	}
+	dbShape := db.shape
+	if got := dbShape.fields["port"].shape.kind; got != kindFloat {
+		t.Errorf("db.port: kind = %d, ожидалось float", got)
+	}
+	if got := dbShape.fields["hosts"].shape.elem; got == nil || got.kind != kindString {
+		t.Errorf("db.hosts: elem = %+v, ожидалась строка", got)
+	}
+	if !dbShape.fields["user"].optional(dbShape) {
+		t.Error("db.user есть только во втором образце и должен быть необязательным")
+	}
+}
+
+func TestMergeShapesTextAndEmpty(t *testing.T) {
+	date := analyzeValue(time.Now(), false)
+	text := analyzeValue("2024-01-01", false)
+	if s := mergeShapes(date, text); s.kind != kindString {
+		t.Errorf("дата и строка: kind = %d, ожидалась строка", s.kind)
+	}
+
+	s := mergeShapes(analyzeValue("x", false), analyzeValue("", false))
+	if !s.empty || s.sample != "x" {
+		t.Errorf("empty = %v, sample = %v, ожидалось true и первый образец", s.empty, s.sample)
+	}
+}
+
+func TestGoLiteral(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		typed bool
+		want  string
+	}{
+		{"localhost", false, `"localhost"`},
+		{`say "hi"`, false, `"say \"hi\""`},
+		{"", false, ""},
+		{8080.0, false, "8080"},
+		{int64(5432), false, "5432"},
+		{0.5, false, "0.5"},
+		{0, false, ""},
+		{true, false, ""},
+		{"0755", true, "755"},
+		{"1e3", true, "1000"},
+		{"inf", true, ""},
+		{time.Now(), false, ""},
+	}
+	for _, tt := range tests {
+		if got := goLiteral(analyzeValue(tt.value, tt.typed)); got != tt.want {
+			t.Errorf("goLiteral(%#v) = %q, ожидалось %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestGeneratorMergesSamples(t *testing.T) {
+	g := NewStructGenerator()
+	samples := []struct {
+		format types.ConfigFormat
+		data   string
+	}{
+		{types.FormatJSON, `{"server": {"port": 8080}, "servers": [{"name": "a"}]}`},
+		{types.FormatYAML, "server:\n  port: 8080.5\n  host: x\nservers:\n  - name: b\n    weight: 1\n"},
+		{types.FormatINI, "[server]\nport = 80\n"},
+	}
+	for _, s := range samples {
+		if err := g.GenerateFromData([]byte(s.data), s.format, "Config"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	code := g.GenerateGoCode()
+	for _, want := range []string{
+		"Port float64",
+		"Host *string",
+		"Servers []ServersItem",
+		"Weight *int64",
+	} {
+		if !strings.Contains(strings.Join(strings.Fields(code), " "), want) {
+			t.Errorf("нет %q в\n%s", want, code)
+		}
+	}
+}
+
+func TestStructTypesIndependentOfMethods(t *testing.T) {
+	g := NewStructGenerator()
+	data := `{"primary": {"host": "a", "port": 1}, "replica": {"host": "b", "port": 2}, "backup": {"host": "a", "port": 1}}`
+	if err := g.GenerateFromJSON([]byte(data), "Config"); err != nil {
+		t.Fatal(err)
+	}
+
+	code := strings.Join(strings.Fields(g.GenerateGoCode()), " ")
+	file, err := g.GenerateGoFile(GoFileOptions{Defaults: true, Validate: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Объекты с разными значениями получают разные типы, одинаковые - общий
+	for _, text := range []string{code, strings.Join(strings.Fields(string(file)), " ")} {
+		for _, want := range []string{"Backup Backup", "Primary Backup", "Replica Replica"} {
+			if !strings.Contains(text, want) {
+				t.Errorf("нет %q в\n%s", want, text)
+			}
+		}
+	}
+}
+
+func TestStructGeneratorSkipsEmptyKey(t *testing.T) {
+	g := NewStructGenerator()
+	if err := g.GenerateFromJSON([]byte(`{"": 1, "name": "a"}`), "Config"); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := g.GenerateGoFile(GoFileOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(file), `json:""`) {
+		t.Errorf("поле с пустым тегом:\n%s", file)
+	}
+	if !strings.Contains(string(file), `json:"name"`) {
+		t.Errorf("нет поля name:\n%s", file)
+	}
+	if warnings := g.Warnings(); len(warnings) != 1 {
+		t.Errorf("предупреждения: %v", warnings)
+	}
+}
+
+// decodeJSON разбирает JSON как парсер JSON
+func decodeJSON(t *testing.T, data string) interface{} {
+	t.Helper()
+	var v interface{}
+	if err := json.Unmarshal([]byte(data), &v); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
@@ -0,0 +1,403 @@
+package generators
+
+// structs.go
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/parsers"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/secrets"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/types"
+)
+
+// DefaultStructTags теги, которые StructGenerator выводит для каждого поля,
+// как в types.CommonConfig
+var DefaultStructTags = []string{"json", "yaml", "ini", "toml"}
+
+// StructField представляет поле структуры; Key - ключ в конфигурации,
+// Default - литерал Go со значением из образца для метода Default,
+// Required - строка, непустая во всех образцах, которую проверяет Validate
+type StructField struct {
+	Name     string
+	Type     string
+	Key      string
+	Optional bool
+	Default  string
+	Required bool
+}
+
+// StructInfo содержит информацию о структуре
+type StructInfo struct {
+	Name   string
+	Fields []StructField
+}
+
+// StructGenerator выводит Go-структуры по конфигурации любого
+// зарегистрированного формата (JSON, YAML, INI, TOML).
+// Числа из разных форматов (float64 JSON, int YAML, int64 TOML)
+// приводятся к int64 или float64, а строки INI - к числам и bool по их виду,
+// поэтому app.json, app.yml, app.ini и app.toml дают одинаковые структуры.
+// Списки INI через запятую неотличимы от текста и остаются строками.
+//
+// Тип элементов массива выводится по всем элементам, а несколько образцов
+// с одним именем корневой структуры сливаются: int расширяется до float64,
+// поля, которых нет в части объектов или которые бывают null, становятся
+// необязательными (указатель и omitempty), а interface{} используется
+// только для действительно несовместимых типов.
+//
+// Имена полей и структур - экспортируемые идентификаторы с аббревиатурами
+// в стиле Go (user_id - UserID). Если одно имя получают объекты с разными
+// полями, каждый из них получает префикс родителя (ClientLimits
+// и ServerLimits), а объекты с одинаковыми полями - общий тип
+type StructGenerator struct {
+	// Tags имена тегов полей; по умолчанию DefaultStructTags
+	Tags []string
+
+	roots     map[string]*shape
+	rootOrder []string
+	structs   map[string]*StructInfo
+	// nodes структуры в порядке обхода, signatures - сигнатура полей и структура
+	nodes      []*structNode
+	nextID     int
+	signatures map[string]*structNode
+	imports    map[string]bool
+	// warnings пропущенные ключи, которые нельзя записать в теги
+	warnings []string
+}
+
+// JSONToStructGenerator прежнее имя StructGenerator из examples/07-json-to-struct
+type JSONToStructGenerator = StructGenerator
+
+// NewStructGenerator создает новый генератор
+func NewStructGenerator() *StructGenerator {
+	return &StructGenerator{
+		Tags:  DefaultStructTags,
+		roots: make(map[string]*shape),
+	}
+}
+
+// NewJSONToStructGenerator создает новый генератор (прежнее имя NewStructGenerator)
+func NewJSONToStructGenerator() *StructGenerator {
+	return NewStructGenerator()
+}
+
+// GenerateFromFile определяет формат файла и анализирует его содержимое.
+// Повторные вызовы с тем же rootStructName сливают образцы в один тип
+func (g *StructGenerator) GenerateFromFile(path, rootStructName string) error {
+	data, format, err := parsers.LoadDynamicFile(path)
+	if err != nil {
+		return err
+	}
+	return g.GenerateFromValue(data, format, rootStructName)
+}
+
+// GenerateFromData анализирует данные указанного формата
+func (g *StructGenerator) GenerateFromData(data []byte, format types.ConfigFormat, rootStructName string) error {
+	value, err := parsers.ParseDynamic(format, data)
+	if err != nil {
+		return fmt.Errorf("не удалось распарсить %s: %w", format, err)
+	}
+	return g.GenerateFromValue(value, format, rootStructName)
+}
+
+// GenerateFromJSON генерирует структуры из JSON данных.
+// Корнем JSON может быть и массив объектов: структура строится по всем элементам
+func (g *StructGenerator) GenerateFromJSON(data []byte, rootStructName string) error {
+	var jsonData interface{}
+	if err := json.Unmarshal(data, &jsonData); err != nil {
+		return fmt.Errorf("не удалось распарсить JSON: %w", err)
+	}
+
+	switch v := jsonData.(type) {
+	case map[string]interface{}:
+		return g.GenerateFromValue(v, types.FormatJSON, rootStructName)
+	case []interface{}:
+		for i, item := range v {
+			obj, ok := item.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("элемент %d корневого массива JSON не является объектом", i)
+			}
+			if err := g.GenerateFromValue(obj, types.FormatJSON, rootStructName+"Item"); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("корневой элемент JSON должен быть объектом или массивом")
+	}
+}
+
+// GenerateFromValue анализирует уже разобранную конфигурацию формата format
+func (g *StructGenerator) GenerateFromValue(value map[string]interface{}, format types.ConfigFormat, rootStructName string) error {
+	s := analyzeObject(value, format == types.FormatINI)
+	if _, exists := g.roots[rootStructName]; !exists {
+		g.rootOrder = append(g.rootOrder, rootStructName)
+	}
+	g.roots[rootStructName] = mergeShapes(g.roots[rootStructName], s)
+	return nil
+}
+
+// Imports возвращает отсортированные пакеты, нужные сгенерированным типам
+func (g *StructGenerator) Imports() []string {
+	g.build()
+
+	imports := make([]string, 0, len(g.imports))
+	for path := range g.imports {
+		imports = append(imports, path)
+	}
+	sort.Strings(imports)
+	return imports
+}
+
+// Warnings возвращает предупреждения о ключах, для которых не создано поле
+func (g *StructGenerator) Warnings() []string {
+	g.build()
+	return g.warnings
+}
+
+// structNode структура, найденная при обходе shape, до выбора имени
+type structNode struct {
+	// id временное имя типа в полях до выбора окончательных имен
+	id     string
+	base   string
+	parent *structNode
+	root   bool
+	name   string
+	fields []StructField
+}
+
+// build строит структуры по накопленным shape в два прохода.
+// Первый проход собирает структуры с временными именами; структурно
+// одинаковые объекты (те же ключи, типы, необязательность, значения по
+// умолчанию и обязательность полей) используют один тип. Значения по
+// умолчанию и обязательность входят в сигнатуру всегда, чтобы набор типов
+// не зависел от того, генерируются ли методы Default и Validate: иначе два
+// объекта с разными значениями из образца получили бы общий Default.
+//
+// Второй проход выбирает имена сверху вниз: имена корней заданы
+// вызывающим, вложенная структура называется по ключу, а если так
+// называются несколько разных структур или корень, каждая из них
+// получает префикс родителя (ClientLimits и ServerLimits). Поэтому имена
+// не зависят от порядка ключей
+func (g *StructGenerator) build() {
+	g.nodes = nil
+	g.nextID = 0
+	g.signatures = make(map[string]*structNode)
+	g.imports = make(map[string]bool)
+	g.warnings = nil
+	for _, name := range g.rootOrder {
+		node := &structNode{base: name, root: true}
+		g.collect(node, g.roots[name])
+	}
+
+	g.nameNodes()
+
+	ids := make([]string, 0, 2*len(g.nodes))
+	for _, node := range g.nodes {
+		ids = append(ids, node.id, node.name)
+	}
+	replacer := strings.NewReplacer(ids...)
+
+	g.structs = make(map[string]*StructInfo, len(g.nodes))
+	for _, node := range g.nodes {
+		for i := range node.fields {
+			node.fields[i].Type = replacer.Replace(node.fields[i].Type)
+		}
+		g.structs[node.name] = &StructInfo{Name: node.name, Fields: node.fields}
+	}
+}
+
+// collect строит поля структуры node и возвращает временное имя ее типа;
+// для структурно одинакового объекта возвращается уже найденный тип
+func (g *StructGenerator) collect(node *structNode, s *shape) string {
+	// Узел добавляется до вложенных, чтобы родитель получил имя раньше них
+	index := len(g.nodes)
+	g.nodes = append(g.nodes, node)
+	g.nextID++
+	node.id = fmt.Sprintf("\x00%d\x00", g.nextID)
+	node.fields = g.structFields(s, node)
+
+	sig := signature(node.fields)
+	existing, ok := g.signatures[sig]
+	if ok && !node.root {
+		// Одинаковая сигнатура значит и одинаковые вложенные типы,
+		// поэтому новых узлов после index нет
+		g.nodes = g.nodes[:index]
+		return existing.id
+	}
+	if !ok {
+		g.signatures[sig] = node
+	}
+	return node.id
+}
+
+// nameNodes выбирает окончательные имена структур. Узлы идут в порядке
+// обхода, поэтому имя родителя уже выбрано
+func (g *StructGenerator) nameNodes() {
+	bases := make(map[string]int)
+	for _, node := range g.nodes {
+		if node.root {
+			bases[node.base] += 2 // имя корня всегда занято
+		} else {
+			bases[node.base]++
+		}
+	}
+
+	taken := make(map[string]bool, len(g.nodes))
+	for _, node := range g.nodes {
+		if node.root {
+			node.name = node.base
+			taken[node.name] = true
+		}
+	}
+
+	for _, node := range g.nodes {
+		if node.root {
+			continue
+		}
+		name := node.base
+		if bases[node.base] > 1 && node.parent != nil {
+			name = node.parent.name + node.base
+		}
+		node.name = uniqueName(name, func(n string) bool { return taken[n] })
+		taken[node.name] = true
+	}
+}
+
+// goType возвращает Go-тип для shape; объекты регистрируются как структуры
+// с временным именем. base - имя, выведенное из ключа, parent - структура-владелец
+func (g *StructGenerator) goType(s *shape, base string, parent *structNode) string {
+	if s == nil {
+		return "interface{}"
+	}
+
+	switch s.kind {
+	case kindBool:
+		return "bool"
+	case kindInt:
+		return "int64"
+	case kindFloat:
+		return "float64"
+	case kindString:
+		return "string"
+	case kindTime:
+		g.imports["time"] = true
+		return "time.Time"
+	case kindArray:
+		return "[]" + g.goType(s.elem, base+"Item", parent)
+	case kindObject:
+		return g.collect(&structNode{base: base, parent: parent}, s)
+	default:
+		return "interface{}"
+	}
+}
+
+// structFields строит поля объекта в порядке ключей
+func (g *StructGenerator) structFields(s *shape, node *structNode) []StructField {
+	keys := make([]string, 0, len(s.fields))
+	for key := range s.fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	fields := make([]StructField, 0, len(keys))
+	names := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		field := s.fields[key]
+
+		// Пустой ключ нельзя задать тегом: json:"" означает имя поля
+		if key == "" {
+			g.warnings = append(g.warnings, fmt.Sprintf("%s: пустой ключ пропущен, его нельзя задать тегом", node.base))
+			continue
+		}
+
+		// Ключи "max-conns" и "max_conns" дают одно имя поля
+		fieldName := uniqueName(exportedName(key), func(n string) bool { return names[n] })
+		names[fieldName] = true
+
+		fieldType := g.goType(field.shape, exportedName(key), node)
+		optional := field.optional(s)
+
+		// Необязательные скаляры и структуры - указатели: так отсутствие
+		// значения отличается от нулевого. Срезы и interface{} уже допускают nil
+		if optional && !strings.HasPrefix(fieldType, "[]") && fieldType != "interface{}" {
+			fieldType = "*" + fieldType
+		}
+
+		// Пароли и токены из образца не попадают в сгенерированный код
+		var defaultValue string
+		if !optional && !secrets.IsSensitive(key) {
+			defaultValue = goLiteral(field.shape)
+		}
+
+		fields = append(fields, StructField{
+			Name:     fieldName,
+			Type:     fieldType,
+			Key:      key,
+			Optional: optional,
+			Default:  defaultValue,
+			Required: fieldType == "string" && !field.shape.empty,
+		})
+	}
+	return fields
+}
+
+// signature описывает поля структуры для поиска одинаковых объектов
+func signature(fields []StructField) string {
+	var b strings.Builder
+	for _, field := range fields {
+		fmt.Fprintf(&b, "%q %s %t %q %t;", field.Key, field.Type, field.Optional, field.Default, field.Required)
+	}
+	return b.String()
+}
+
+// GenerateGoCode генерирует Go код структур
+func (g *StructGenerator) GenerateGoCode() string {
+	g.build()
+
+	var builder strings.Builder
+
+	builder.WriteString("// Автоматически сгенерированные структуры конфигурации\n\n")
+
+	// Сортируем структуры по имени
+	structNames := make([]string, 0, len(g.structs))
+	for name := range g.structs {
+		structNames = append(structNames, name)
+	}
+	sort.Strings(structNames)
+
+	for _, name := range structNames {
+		structInfo := g.structs[name]
+		builder.WriteString(fmt.Sprintf("type %s struct {\n", structInfo.Name))
+
+		for _, field := range structInfo.Fields {
+			builder.WriteString(fmt.Sprintf("\t%s %s %s\n",
+				field.Name, field.Type, g.fieldTag(field)))
+		}
+
+		builder.WriteString("}\n\n")
+	}
+
+	return builder.String()
+}
+
+// fieldTag возвращает теги поля: `json:"key" yaml:"key" ini:"key" toml:"key"`
+func (g *StructGenerator) fieldTag(field StructField) string {
+	tags := g.Tags
+	if tags == nil {
+		tags = DefaultStructTags
+	}
+
+	parts := make([]string, len(tags))
+	for i, tag := range tags {
+		value := field.Key
+		if field.Optional {
+			value += ",omitempty"
+		}
+		parts[i] = fmt.Sprintf("%s:%q", tag, value)
+	}
+	return "`" + strings.Join(parts, " ") + "`"
+}
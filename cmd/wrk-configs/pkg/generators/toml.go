@@ -0,0 +1,80 @@
+package generators
+
+// toml.go
+
+import (
+	"bytes"
+
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/types"
+	"github.com/pelletier/go-toml/v2"
+)
+
+// TOMLGenerator сериализует конфигурации в TOML.
+// Ключи map выводятся в отсортированном порядке, поля структур - в порядке объявления.
+// В TOML нет null, поэтому nil-значения динамических map пропускаются,
+// а целые float64 (так JSONParser.ParseDynamic хранит числа) выводятся как целые
+type TOMLGenerator struct {
+	// Indent строка отступа вложенных таблиц; пустая строка отключает отступы
+	Indent string
+}
+
+func init() {
+	Register(NewTOMLGenerator())
+}
+
+func NewTOMLGenerator() *TOMLGenerator {
+	return &TOMLGenerator{}
+}
+
+func (g *TOMLGenerator) Generate(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+
+	encoder := toml.NewEncoder(&buf)
+	if g.Indent != "" {
+		encoder.SetIndentTables(true)
+		encoder.SetIndentSymbol(g.Indent)
+	}
+	if m, ok := asMap(v); ok {
+		v = normalizeTOML(m)
+	}
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (g *TOMLGenerator) Format() types.ConfigFormat {
+	return types.FormatTOML
+}
+
+// normalizeTOML готовит динамическое значение к кодированию в TOML
+func normalizeTOML(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			if item == nil {
+				continue
+			}
+			result[key] = normalizeTOML(item)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			if item == nil {
+				continue
+			}
+			result = append(result, normalizeTOML(item))
+		}
+		return result
+	case float64:
+		if isIntegral(v) {
+			return int64(v)
+		}
+		return v
+	default:
+		return v
+	}
+}
@@ -0,0 +1,27 @@
+package generators
+
+// utils.go
+
+import "math"
+
+// asMap возвращает динамическую map, если v является map[string]interface{}
+// или указателем на нее
+func asMap(v interface{}) (map[string]interface{}, bool) {
+	switch m := v.(type) {
+	case map[string]interface{}:
+		return m, true
+	case *map[string]interface{}:
+		if m == nil {
+			return nil, false
+		}
+		return *m, true
+	default:
+		return nil, false
+	}
+}
+
+// isIntegral проверяет, что число с плавающей точкой является целым
+// и представимо в int64
+func isIntegral(f float64) bool {
+	return f == math.Trunc(f) && f >= math.MinInt64 && f <= math.MaxInt64
+}
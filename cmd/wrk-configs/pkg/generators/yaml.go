@@ -0,0 +1,46 @@
+package generators
+
+// yaml.go
+
+import (
+	"bytes"
+
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/types"
+	"gopkg.in/yaml.v3"
+)
+
+// YAMLGenerator сериализует конфигурации в YAML.
+// Ключи map выводятся в отсортированном порядке, поля структур - в порядке объявления
+type YAMLGenerator struct {
+	// Indent количество пробелов на уровень вложенности
+	Indent int
+}
+
+func init() {
+	Register(NewYAMLGenerator())
+}
+
+func NewYAMLGenerator() *YAMLGenerator {
+	return &YAMLGenerator{Indent: 2}
+}
+
+func (g *YAMLGenerator) Generate(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+
+	encoder := yaml.NewEncoder(&buf)
+	if g.Indent > 0 {
+		encoder.SetIndent(g.Indent)
+	}
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (g *YAMLGenerator) Format() types.ConfigFormat {
+	return types.FormatYAML
+}
@@ -0,0 +1,219 @@
+package include
+
+// include.go
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/merge"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/parsers"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/utils"
+)
+
+// DefaultKey ключ директивы включения. Работает в любом формате и на любом уровне:
+//
+//	JSON:  {"$include": ["base.json", "conf.d/*.yaml"]}
+//	YAML:  $include: conf.d/*.yaml
+//	TOML:  "$include" = "db.toml"
+//	INI:   $include = base.ini, conf.d/*.ini   (в секции - включение в эту секцию)
+//
+// Пути задаются относительно включающего файла, поддерживаются шаблоны
+// filepath.Glob. Включенные файлы сливаются по порядку (результаты шаблона -
+// по алфавиту), собственные ключи объекта с директивой перекрывают включенные
+const DefaultKey = "$include"
+
+// Loader загружает файл, раскрывая директивы включения
+type Loader struct {
+	// Key ключ директивы, по умолчанию DefaultKey
+	Key string
+	// Arrays правило слияния массивов включенных файлов
+	Arrays merge.ArrayRule
+}
+
+// Result загруженная конфигурация и все прочитанные файлы
+type Result struct {
+	Data map[string]interface{}
+	// Files пути всех прочитанных файлов в порядке чтения, начиная с корневого
+	// (например, чтобы следить за ними через watcher)
+	Files []string
+}
+
+// CycleError цикл включений; Chain - цепочка файлов от корневого до повторного
+type CycleError struct {
+	Chain []string
+}
+
+func (e *CycleError) Error() string {
+	return "цикл включений: " + strings.Join(e.Chain, " -> ")
+}
+
+// NewLoader создает загрузчик с ключом $include и заменой массивов
+func NewLoader() *Loader {
+	return &Loader{Key: DefaultKey}
+}
+
+// LoadFile загружает файл загрузчиком по умолчанию
+func LoadFile(path string) (*Result, error) {
+	return NewLoader().LoadFile(path)
+}
+
+// LoadFile загружает файл любого формата и рекурсивно раскрывает включения
+func (l *Loader) LoadFile(path string) (*Result, error) {
+	result := &Result{}
+	data, err := l.load(path, nil, result)
+	if err != nil {
+		return nil, err
+	}
+	result.Data = data
+	return result, nil
+}
+
+// load читает один файл; stack - цепочка включающих файлов для обнаружения циклов
+func (l *Loader) load(path string, stack []string, result *Result) (map[string]interface{}, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	for i, included := range stack {
+		if included == abs {
+			chain := append(append([]string(nil), stack[i:]...), abs)
+			return nil, &CycleError{Chain: relativeChain(chain)}
+		}
+	}
+
+	data, _, err := parsers.LoadDynamicFile(path)
+	if err != nil {
+		return nil, err
+	}
+	result.Files = append(result.Files, path)
+
+	if err := l.expand(data, filepath.Dir(path), append(stack, abs), result); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// expand раскрывает директивы в объекте obj и во всех вложенных объектах
+func (l *Loader) expand(obj map[string]interface{}, dir string, stack []string, result *Result) error {
+	for _, value := range obj {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			if err := l.expand(v, dir, stack, result); err != nil {
+				return err
+			}
+		case []interface{}:
+			for _, item := range v {
+				if m, ok := item.(map[string]interface{}); ok {
+					if err := l.expand(m, dir, stack, result); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+
+	directive, exists := obj[l.key()]
+	if !exists {
+		return nil
+	}
+	delete(obj, l.key())
+
+	patterns, err := l.patterns(directive)
+	if err != nil {
+		return fmt.Errorf("%s: %w", stack[len(stack)-1], err)
+	}
+
+	merger := merge.NewMerger()
+	merger.Arrays = l.Arrays
+
+	for _, pattern := range patterns {
+		files, err := resolve(dir, pattern)
+		if err != nil {
+			return fmt.Errorf("%s: %w", stack[len(stack)-1], err)
+		}
+		for _, file := range files {
+			data, err := l.load(file, stack, result)
+			if err != nil {
+				return err
+			}
+			merger.Add(merge.MapSource(file, data))
+		}
+	}
+
+	merger.Add(merge.MapSource(stack[len(stack)-1], obj))
+	merged, err := merger.Merge()
+	if err != nil {
+		return err
+	}
+
+	for key := range obj {
+		delete(obj, key)
+	}
+	for key, value := range merged.Data {
+		obj[key] = value
+	}
+	return nil
+}
+
+func (l *Loader) key() string {
+	if l.Key == "" {
+		return DefaultKey
+	}
+	return l.Key
+}
+
+// patterns разбирает значение директивы: строку (в INI - список через запятую) или массив строк
+func (l *Loader) patterns(directive interface{}) ([]string, error) {
+	switch v := directive.(type) {
+	case string:
+		return utils.SplitList(v), nil
+	case []interface{}:
+		patterns := make([]string, len(v))
+		for i, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s[%d]: ожидается путь к файлу, получено %T", l.key(), i, item)
+			}
+			patterns[i] = s
+		}
+		return patterns, nil
+	default:
+		return nil, fmt.Errorf("%s: ожидается путь или список путей, получено %T", l.key(), directive)
+	}
+}
+
+// resolve находит файлы по пути или шаблону относительно dir.
+// Шаблон может не совпасть ни с одним файлом, обычный путь должен существовать
+func resolve(dir, pattern string) ([]string, error) {
+	if !filepath.IsAbs(pattern) {
+		pattern = filepath.Join(dir, pattern)
+	}
+
+	if !strings.ContainsAny(pattern, "*?[") {
+		return []string{pattern}, nil
+	}
+
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("неверный шаблон %s: %w", pattern, err)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// relativeChain сокращает пути цепочки относительно текущей директории
+func relativeChain(chain []string) []string {
+	wd, _ := os.Getwd()
+	result := make([]string, len(chain))
+	for i, path := range chain {
+		result[i] = path
+		if rel, err := filepath.Rel(wd, path); err == nil && !strings.HasPrefix(rel, "..") {
+			result[i] = rel
+		}
+	}
+	return result
+}
@@ -0,0 +1,144 @@
+package include
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeFiles создает файлы во временной директории и возвращает ее путь
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoadFile(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"app.json":         `{"$include": ["base.yaml", "conf.d/*.toml"], "name": "app", "server": {"port": 9090}}`,
+		"base.yaml":        "name: base\nserver:\n  host: localhost\n  port: 8080\nlist: [1, 2]\n",
+		"conf.d/10-a.toml": "[server]\ndebug = true\n",
+		"conf.d/20-b.toml": "[server]\ndebug = false\n[db]\n\"$include\" = \"../db.ini\"\nuser = \"app\"\n",
+		"db.ini":           "host = db\nuser = root\n",
+	})
+
+	result, err := LoadFile(filepath.Join(dir, "app.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]interface{}{
+		"name": "app",
+		"server": map[string]interface{}{
+			"host":  "localhost",
+			"port":  9090.0,
+			"debug": false,
+		},
+		"list": []interface{}{1, 2},
+		"db":   map[string]interface{}{"host": "db", "user": "app"},
+	}
+	if !reflect.DeepEqual(result.Data, want) {
+		t.Errorf("получено %#v", result.Data)
+	}
+
+	var files []string
+	for _, file := range result.Files {
+		rel, _ := filepath.Rel(dir, file)
+		files = append(files, filepath.ToSlash(rel))
+	}
+	wantFiles := []string{"app.json", "base.yaml", "conf.d/10-a.toml", "conf.d/20-b.toml", "db.ini"}
+	if !reflect.DeepEqual(files, wantFiles) {
+		t.Errorf("Files = %q, ожидалось %q", files, wantFiles)
+	}
+}
+
+func TestIncludeInArrayAndINISection(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"app.yaml":    "servers:\n  - $include: server.json\n    name: a\n",
+		"server.json": `{"name": "default", "port": 1}`,
+		"app.ini":     "[db]\n$include = db.ini, missing-*.ini\nport = 5433\n",
+		"db.ini":      "host = localhost\nport = 5432\n",
+	})
+
+	result, err := LoadFile(filepath.Join(dir, "app.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []interface{}{map[string]interface{}{"name": "a", "port": 1.0}}
+	if !reflect.DeepEqual(result.Data["servers"], want) {
+		t.Errorf("servers = %#v", result.Data["servers"])
+	}
+
+	result, err = LoadFile(filepath.Join(dir, "app.ini"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantDB := map[string]interface{}{"host": "localhost", "port": "5433"}
+	if !reflect.DeepEqual(result.Data["db"], wantDB) {
+		t.Errorf("db = %#v", result.Data["db"])
+	}
+}
+
+func TestCycle(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a.yaml":    "$include: b.yaml\n",
+		"b.yaml":    "$include: c.yaml\n",
+		"c.yaml":    "$include: a.yaml\n",
+		"self.json": `{"$include": "self.json"}`,
+	})
+
+	_, err := LoadFile(filepath.Join(dir, "a.yaml"))
+	var cycle *CycleError
+	if !errors.As(err, &cycle) {
+		t.Fatalf("ожидалась CycleError, получено %v", err)
+	}
+	if len(cycle.Chain) != 4 || filepath.Base(cycle.Chain[0]) != "a.yaml" || filepath.Base(cycle.Chain[3]) != "a.yaml" {
+		t.Errorf("Chain = %q", cycle.Chain)
+	}
+
+	if _, err := LoadFile(filepath.Join(dir, "self.json")); !errors.As(err, &cycle) {
+		t.Errorf("включение самого себя: %v", err)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"missing.yaml":  "$include: nope.yaml\n",
+		"bad.json":      `{"$include": 1}`,
+		"bad-item.json": `{"$include": ["a.json", 2]}`,
+		"pattern.yaml":  "$include: \"[\"\n",
+	})
+	for _, name := range []string{"missing.yaml", "bad.json", "bad-item.json", "pattern.yaml"} {
+		if _, err := LoadFile(filepath.Join(dir, name)); err == nil {
+			t.Errorf("%s: ожидалась ошибка", name)
+		}
+	}
+}
+
+func TestCustomKey(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"app.json":  `{"@import": "base.json", "$include": "kept"}`,
+		"base.json": `{"a": 1}`,
+	})
+	l := NewLoader()
+	l.Key = "@import"
+	result, err := l.LoadFile(filepath.Join(dir, "app.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{"a": 1.0, "$include": "kept"}
+	if !reflect.DeepEqual(result.Data, want) {
+		t.Errorf("получено %v", result.Data)
+	}
+}
@@ -0,0 +1,235 @@
+package interpolate
+
+// interpolate.go
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/keypath"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/utils"
+)
+
+// Подстановки в строковых значениях конфигурации:
+//
+//	${database.host}         значение другого ключа (язык путей keypath)
+//	${env:HOME}              переменная окружения
+//	${file:/run/secrets/db}  содержимое файла без завершающего перевода строки
+//	$${literal}              экранирование: остается "${literal}"
+//
+// Если строка целиком состоит из одной ссылки на ключ, подставляется
+// значение с исходным типом ("${server.port}" -> 8080), иначе - его текст
+
+// Provider возвращает значение подстановки "${name:arg}" по аргументу.
+// ok == false означает, что значение не найдено
+type Provider func(arg string) (value string, ok bool, err error)
+
+// Interpolator раскрывает подстановки в динамической конфигурации
+type Interpolator struct {
+	// Strict превращает неразрешенные ссылки в ошибки;
+	// иначе вместо них подставляется пустая строка
+	Strict bool
+	// Providers обработчики префиксов "${name:...}"; по умолчанию env и file
+	Providers map[string]Provider
+}
+
+// New создает строгий интерполятор с провайдерами env и file
+func New() *Interpolator {
+	return &Interpolator{
+		Strict: true,
+		Providers: map[string]Provider{
+			"env":  EnvProvider(os.LookupEnv),
+			"file": FileProvider(os.ReadFile),
+		},
+	}
+}
+
+// EnvProvider провайдер переменных окружения
+func EnvProvider(lookup func(string) (string, bool)) Provider {
+	return func(name string) (string, bool, error) {
+		value, ok := lookup(name)
+		return value, ok, nil
+	}
+}
+
+// FileProvider провайдер содержимого файлов (секреты Docker и Kubernetes)
+func FileProvider(read func(string) ([]byte, error)) Provider {
+	return func(path string) (string, bool, error) {
+		data, err := read(path)
+		if errors.Is(err, os.ErrNotExist) {
+			return "", false, nil
+		}
+		if err != nil {
+			return "", false, err
+		}
+		return strings.TrimRight(string(data), "\r\n"), true, nil
+	}
+}
+
+// Apply раскрывает подстановки во всех строках data на месте.
+// Возвращает все ошибки сразу, объединенные errors.Join
+func (ip *Interpolator) Apply(data map[string]interface{}) error {
+	r := &run{ip: ip, root: data, done: make(map[string]interface{})}
+	for _, key := range utils.SortedKeys(data) {
+		data[key] = r.node(keypath.Join("", key), data[key])
+	}
+	return errors.Join(r.errs...)
+}
+
+// Expand раскрывает подстановки в одной строке; ссылки на ключи ищутся в data
+func (ip *Interpolator) Expand(s string, data map[string]interface{}) (interface{}, error) {
+	r := &run{ip: ip, root: data, done: make(map[string]interface{})}
+	value := r.expand("", s)
+	return value, errors.Join(r.errs...)
+}
+
+// run состояние одного вызова Apply
+type run struct {
+	ip    *Interpolator
+	root  map[string]interface{}
+	done  map[string]interface{}
+	stack []string
+	errs  []error
+}
+
+func (r *run) fail(path, format string, args ...interface{}) {
+	if path == "" {
+		r.errs = append(r.errs, fmt.Errorf(format, args...))
+		return
+	}
+	r.errs = append(r.errs, fmt.Errorf("%s: %s", path, fmt.Sprintf(format, args...)))
+}
+
+// node раскрывает подстановки в значении и возвращает результат
+func (r *run) node(path string, value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		if resolved, ok := r.done[path]; ok {
+			return resolved
+		}
+		for i, p := range r.stack {
+			if p == path {
+				chain := append(append([]string(nil), r.stack[i:]...), path)
+				r.fail(path, "циклическая ссылка: %s", strings.Join(chain, " -> "))
+				return ""
+			}
+		}
+
+		r.stack = append(r.stack, path)
+		resolved := r.expand(path, v)
+		r.stack = r.stack[:len(r.stack)-1]
+
+		r.done[path] = resolved
+		return resolved
+
+	case map[string]interface{}:
+		for _, key := range utils.SortedKeys(v) {
+			v[key] = r.node(keypath.Join(path, key), v[key])
+		}
+		return v
+
+	case []interface{}:
+		for i, item := range v {
+			v[i] = r.node(keypath.Index(path, i), item)
+		}
+		return v
+	}
+	return value
+}
+
+// expand раскрывает подстановки строки, находящейся по пути path
+func (r *run) expand(path, s string) interface{} {
+	if !strings.Contains(s, "${") {
+		return s
+	}
+
+	// Строка из одной ссылки сохраняет тип значения
+	if strings.HasPrefix(s, "${") && strings.Index(s, "}") == len(s)-1 && !strings.Contains(s[2:], "${") {
+		value, _ := r.resolve(path, s[2:len(s)-1])
+		return utils.DeepCopy(value)
+	}
+
+	var b strings.Builder
+	for {
+		start := strings.Index(s, "${")
+		if start < 0 {
+			b.WriteString(s)
+			break
+		}
+
+		// $${...} - экранированная подстановка
+		if start > 0 && s[start-1] == '$' {
+			b.WriteString(s[:start-1])
+			b.WriteString("${")
+			s = s[start+2:]
+			continue
+		}
+
+		end := strings.Index(s[start:], "}")
+		if end < 0 {
+			r.fail(path, "незакрытая подстановка в %q", s)
+			b.WriteString(s)
+			break
+		}
+
+		b.WriteString(s[:start])
+		value, ok := r.resolve(path, s[start+2:start+end])
+		if ok {
+			switch value.(type) {
+			case map[string]interface{}, []interface{}:
+				r.fail(path, "подстановка ${%s}: объект или массив нельзя вставить в строку", s[start+2:start+end])
+			default:
+				b.WriteString(fmt.Sprint(value))
+			}
+		}
+		s = s[start+end+1:]
+	}
+	return b.String()
+}
+
+// resolve вычисляет выражение подстановки. При неудаче в нестрогом
+// режиме возвращает пустую строку, в строгом - регистрирует ошибку
+func (r *run) resolve(path, expr string) (interface{}, bool) {
+	expr = strings.TrimSpace(expr)
+
+	if name, arg, ok := strings.Cut(expr, ":"); ok {
+		if provider, exists := r.ip.Providers[name]; exists {
+			value, found, err := provider(arg)
+			switch {
+			case err != nil:
+				r.fail(path, "подстановка ${%s}: %v", expr, err)
+				return "", false
+			case !found:
+				return r.unresolved(path, expr)
+			}
+			return value, true
+		}
+	}
+
+	ref, err := keypath.Parse(expr)
+	if err != nil {
+		r.fail(path, "подстановка ${%s}: %v", expr, err)
+		return "", false
+	}
+	if ref.HasWildcard() {
+		r.fail(path, "подстановка ${%s}: шаблоны не поддерживаются", expr)
+		return "", false
+	}
+
+	matches := ref.Find(r.root)
+	if len(matches) == 0 {
+		return r.unresolved(path, expr)
+	}
+	return r.node(matches[0].Path, matches[0].Value), true
+}
+
+// unresolved обрабатывает ненайденную подстановку согласно Strict
+func (r *run) unresolved(path, expr string) (interface{}, bool) {
+	if r.ip.Strict {
+		r.fail(path, "подстановка ${%s} не найдена", expr)
+		return "", false
+	}
+	return "", true
+}
@@ -0,0 +1,151 @@
+package interpolate
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// newTest создает интерполятор с фиксированным окружением и файлами
+func newTest() *Interpolator {
+	ip := New()
+	ip.Providers["env"] = EnvProvider(func(name string) (string, bool) {
+		value, ok := map[string]string{"HOME": "/home/app", "EMPTY": ""}[name]
+		return value, ok
+	})
+	ip.Providers["file"] = FileProvider(func(path string) ([]byte, error) {
+		if path == "/run/secrets/db" {
+			return []byte("s3cr3t\n"), nil
+		}
+		if path == "/denied" {
+			return nil, os.ErrPermission
+		}
+		return nil, os.ErrNotExist
+	})
+	return ip
+}
+
+func TestApply(t *testing.T) {
+	data := map[string]interface{}{
+		"server": map[string]interface{}{"host": "localhost", "port": 8080},
+		"url":    "http://${server.host}:${server.port}/",
+		"port":   "${server.port}",
+		"copy":   "${server}",
+		"home":   "${env:HOME}/data",
+		"empty":  "[${env:EMPTY}]",
+		"pass":   "${file:/run/secrets/db}",
+		"chain":  "${url}api",
+		"list":   []interface{}{"${server.host}", "${list[0]}!"},
+		"escape": "$${server.host} ${ server.host }",
+		"plain":  "no refs $ {x}",
+	}
+	if err := newTest().Apply(data); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]interface{}{
+		"server": map[string]interface{}{"host": "localhost", "port": 8080},
+		"url":    "http://localhost:8080/",
+		"port":   8080,
+		"copy":   map[string]interface{}{"host": "localhost", "port": 8080},
+		"home":   "/home/app/data",
+		"empty":  "[]",
+		"pass":   "s3cr3t",
+		"chain":  "http://localhost:8080/api",
+		"list":   []interface{}{"localhost", "localhost!"},
+		"escape": "${server.host} localhost",
+		"plain":  "no refs $ {x}",
+	}
+	if !reflect.DeepEqual(data, want) {
+		t.Errorf("получено %#v", data)
+	}
+
+	// Подставленный объект не общий с исходным
+	data["copy"].(map[string]interface{})["host"] = "changed"
+	if data["server"].(map[string]interface{})["host"] != "localhost" {
+		t.Error("копия объекта связана с исходным ключом")
+	}
+}
+
+func TestCycles(t *testing.T) {
+	tests := []struct {
+		name string
+		data map[string]interface{}
+	}{
+		{"ссылка на себя", map[string]interface{}{"a": "${a}"}},
+		{"два ключа", map[string]interface{}{"a": "x${b}", "b": "y${a}"}},
+		{"три ключа", map[string]interface{}{"a": "${b}", "b": "${c}", "c": "${a}"}},
+		{"через объект", map[string]interface{}{"a": map[string]interface{}{"b": "${a}"}}},
+	}
+	for _, tt := range tests {
+		err := newTest().Apply(tt.data)
+		if err == nil || !strings.Contains(err.Error(), "циклическая ссылка") {
+			t.Errorf("%s: ошибка = %v", tt.name, err)
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		value   string
+		message string
+	}{
+		{"${missing}", "не найдена"},
+		{"${env:NOPE}", "не найдена"},
+		{"${file:/nope}", "не найдена"},
+		{"${file:/denied}", "permission denied"},
+		{"x ${server}", "объект или массив"},
+		{"${list[*]}", "шаблоны"},
+		{"${a..}", "подстановка"},
+		{"x ${open", "незакрытая"},
+	}
+	for _, tt := range tests {
+		data := map[string]interface{}{
+			"value":  tt.value,
+			"server": map[string]interface{}{"host": "x"},
+			"list":   []interface{}{1},
+		}
+		err := newTest().Apply(data)
+		if err == nil || !strings.Contains(err.Error(), tt.message) || !strings.Contains(err.Error(), "value:") {
+			t.Errorf("%q: ошибка = %v, ожидалось %q", tt.value, err, tt.message)
+		}
+	}
+
+	// Все ошибки возвращаются сразу
+	err := newTest().Apply(map[string]interface{}{"a": "${x}", "b": "${y}"})
+	if err == nil || !strings.Contains(err.Error(), "a:") || !strings.Contains(err.Error(), "b:") {
+		t.Errorf("ошибка = %v", err)
+	}
+}
+
+func TestNotStrict(t *testing.T) {
+	ip := newTest()
+	ip.Strict = false
+	data := map[string]interface{}{"a": "[${missing}]", "b": "${env:NOPE}"}
+	if err := ip.Apply(data); err != nil {
+		t.Fatal(err)
+	}
+	if data["a"] != "[]" || data["b"] != "" {
+		t.Errorf("получено %v", data)
+	}
+}
+
+func TestExpandAndCustomProvider(t *testing.T) {
+	ip := newTest()
+	ip.Providers["upper"] = func(arg string) (string, bool, error) {
+		if arg == "" {
+			return "", false, errors.New("пустой аргумент")
+		}
+		return strings.ToUpper(arg), true, nil
+	}
+
+	value, err := ip.Expand("${upper:abc}-${name}", map[string]interface{}{"name": "x"})
+	if err != nil || value != "ABC-x" {
+		t.Errorf("Expand = %v, %v", value, err)
+	}
+	if _, err := ip.Expand("${upper:}", nil); err == nil {
+		t.Error("ошибка провайдера не возвращена")
+	}
+}
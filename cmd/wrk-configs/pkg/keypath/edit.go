@@ -0,0 +1,186 @@
+package keypath
+
+// edit.go
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// Set записывает значение по пути, создавая недостающие промежуточные
+// объекты и массивы: ключ создает объект, индекс - массив.
+// Индекс за концом массива дополняет его значениями nil.
+// Пути с шаблонами не поддерживаются
+func (p Path) Set(root map[string]interface{}, value interface{}) error {
+	if err := p.checkEditable(); err != nil {
+		return err
+	}
+	_, err := setIn(root, p, Normalize(value), "")
+	return err
+}
+
+// Delete удаляет значение по пути. Элементы массива после удаленного сдвигаются
+func (p Path) Delete(root map[string]interface{}) error {
+	if err := p.checkEditable(); err != nil {
+		return err
+	}
+
+	parent, last := p[:len(p)-1], p[len(p)-1]
+	matches := parent.Find(root)
+	if len(matches) == 0 {
+		return fmt.Errorf("ключ '%s' не найден", p)
+	}
+
+	switch node := matches[0].Value.(type) {
+	case map[string]interface{}:
+		if last.Kind != KindKey {
+			return fmt.Errorf("путь '%s': значение '%s' является объектом, а не массивом", p, parent)
+		}
+		if _, exists := node[last.Key]; !exists {
+			return fmt.Errorf("ключ '%s' не найден", p)
+		}
+		delete(node, last.Key)
+		return nil
+
+	case []interface{}:
+		if last.Kind != KindIndex {
+			return fmt.Errorf("путь '%s': значение '%s' является массивом, а не объектом", p, parent)
+		}
+		i := last.Index
+		if i < 0 {
+			i += len(node)
+		}
+		if i < 0 || i >= len(node) {
+			return fmt.Errorf("ключ '%s' не найден", p)
+		}
+		trimmed := append(node[:i:i], node[i+1:]...)
+		return parent.Set(root, trimmed)
+
+	default:
+		return fmt.Errorf("ключ '%s' не найден", p)
+	}
+}
+
+// checkEditable проверяет, что путь указывает ровно на одно значение
+func (p Path) checkEditable() error {
+	if len(p) == 0 {
+		return fmt.Errorf("пустой путь")
+	}
+	if p.HasWildcard() {
+		return fmt.Errorf("путь '%s' содержит шаблон и не может использоваться для изменения", p)
+	}
+	return nil
+}
+
+// setIn рекурсивно записывает значение и возвращает обновленный узел,
+// так как при расширении массива меняется сам срез
+func setIn(node interface{}, p Path, value interface{}, prefix string) (interface{}, error) {
+	if len(p) == 0 {
+		return value, nil
+	}
+
+	seg, rest := p[0], p[1:]
+
+	switch seg.Kind {
+	case KindKey:
+		obj, ok := node.(map[string]interface{})
+		if node == nil {
+			obj, ok = make(map[string]interface{}), true
+		}
+		if !ok {
+			return nil, fmt.Errorf("значение по ключу '%s' не является объектом", prefix)
+		}
+		path := Join(prefix, seg.Key)
+		child, err := setIn(obj[seg.Key], rest, value, path)
+		if err != nil {
+			return nil, err
+		}
+		obj[seg.Key] = child
+		return obj, nil
+
+	case KindIndex:
+		arr, ok := node.([]interface{})
+		if node == nil {
+			ok = true
+		}
+		if !ok {
+			return nil, fmt.Errorf("значение по ключу '%s' не является массивом", prefix)
+		}
+		i := seg.Index
+		if i < 0 {
+			i += len(arr)
+			if i < 0 {
+				return nil, fmt.Errorf("индекс %d вне массива '%s' длины %d", seg.Index, prefix, len(arr))
+			}
+		}
+		for len(arr) <= i {
+			arr = append(arr, nil)
+		}
+		child, err := setIn(arr[i], rest, value, Index(prefix, i))
+		if err != nil {
+			return nil, err
+		}
+		arr[i] = child
+		return arr, nil
+	}
+
+	return nil, fmt.Errorf("путь '%s' содержит шаблон и не может использоваться для изменения", p)
+}
+
+// Normalize приводит значение к виду, в котором парсеры возвращают
+// динамические данные: срезы и массивы - к []interface{},
+// карты со строковыми ключами - к map[string]interface{},
+// структуры - через JSON к map[string]interface{}
+func Normalize(value interface{}) interface{} {
+	switch value.(type) {
+	case nil, map[string]interface{}, []interface{}:
+		return value
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return value
+		}
+		result := make([]interface{}, rv.Len())
+		for i := range result {
+			result[i] = Normalize(rv.Index(i).Interface())
+		}
+		return result
+
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return value
+		}
+		result := make(map[string]interface{}, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			result[iter.Key().String()] = Normalize(iter.Value().Interface())
+		}
+		return result
+
+	case reflect.Struct:
+		if _, ok := value.(interface{ MarshalJSON() ([]byte, error) }); ok {
+			return value
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return value
+		}
+		var result map[string]interface{}
+		if err := json.Unmarshal(data, &result); err != nil {
+			return value
+		}
+		return result
+
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return nil
+		}
+		return Normalize(rv.Elem().Interface())
+	}
+
+	return value
+}
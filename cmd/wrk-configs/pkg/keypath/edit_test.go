@@ -0,0 +1,135 @@
+package keypath
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSet(t *testing.T) {
+	tests := []struct {
+		name  string
+		doc   string
+		expr  string
+		value interface{}
+		want  string
+	}{
+		{"замена значения", `{"a":{"b":1}}`, "a.b", 2.0, `{"a":{"b":2}}`},
+		{"создание объектов", `{}`, "a.b.c", "x", `{"a":{"b":{"c":"x"}}}`},
+		{"создание массива", `{}`, "a[1]", "x", `{"a":[null,"x"]}`},
+		{"дополнение массива", `{"a":[1]}`, "a[2]", 3.0, `{"a":[1,null,3]}`},
+		{"индекс с конца", `{"a":[1,2]}`, "a[-1]", 5.0, `{"a":[1,5]}`},
+		{"объект в массиве", `{"a":[{"b":1}]}`, "a[0].c", true, `{"a":[{"b":1,"c":true}]}`},
+		{"ключ с точкой", `{}`, `a["b.c"]`, 1.0, `{"a":{"b.c":1}}`},
+		{"срез приводится к []interface{}", `{}`, "a", []string{"x", "y"}, `{"a":["x","y"]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := decode(t, tt.doc)
+			if err := MustParse(tt.expr).Set(root, tt.value); err != nil {
+				t.Fatal(err)
+			}
+			if want := decode(t, tt.want); !reflect.DeepEqual(root, want) {
+				t.Errorf("получено %v, ожидалось %v", root, want)
+			}
+		})
+	}
+}
+
+func TestSetErrors(t *testing.T) {
+	tests := []struct{ doc, expr string }{
+		{`{"a":1}`, "a.b"},
+		{`{"a":{}}`, "a[0]"},
+		{`{"a":[1]}`, "a[-2]"},
+		{`{"a":[1]}`, "a[*]"},
+		{`{"a":1}`, "..a"},
+	}
+	for _, tt := range tests {
+		root := decode(t, tt.doc)
+		if err := MustParse(tt.expr).Set(root, 1); err == nil {
+			t.Errorf("Set(%s, %q): ожидалась ошибка, получено %v", tt.doc, tt.expr, root)
+		}
+	}
+	if err := Path(nil).Set(map[string]interface{}{}, 1); err == nil {
+		t.Error("Set с пустым путем: ожидалась ошибка")
+	}
+}
+
+func TestDelete(t *testing.T) {
+	tests := []struct {
+		doc, expr, want string
+	}{
+		{`{"a":{"b":1,"c":2}}`, "a.b", `{"a":{"c":2}}`},
+		{`{"a":[1,2,3]}`, "a[1]", `{"a":[1,3]}`},
+		{`{"a":[1,2,3]}`, "a[-1]", `{"a":[1,2]}`},
+		{`{"a":[{"b":1}]}`, "a[0].b", `{"a":[{}]}`},
+	}
+	for _, tt := range tests {
+		root := decode(t, tt.doc)
+		if err := MustParse(tt.expr).Delete(root); err != nil {
+			t.Errorf("Delete(%s, %q): %v", tt.doc, tt.expr, err)
+			continue
+		}
+		if want := decode(t, tt.want); !reflect.DeepEqual(root, want) {
+			t.Errorf("Delete(%s, %q) = %v, ожидалось %v", tt.doc, tt.expr, root, want)
+		}
+	}
+}
+
+func TestDeleteErrors(t *testing.T) {
+	tests := []struct{ doc, expr string }{
+		{`{"a":1}`, "b"},
+		{`{"a":{}}`, "a.b"},
+		{`{"a":[1]}`, "a[1]"},
+		{`{"a":[1]}`, "a.b"},
+		{`{"a":{}}`, "a[0]"},
+		{`{"a":[1]}`, "a[*]"},
+	}
+	for _, tt := range tests {
+		if err := MustParse(tt.expr).Delete(decode(t, tt.doc)); err == nil {
+			t.Errorf("Delete(%s, %q): ожидалась ошибка", tt.doc, tt.expr)
+		}
+	}
+}
+
+func TestDeleteKeepsOtherSlices(t *testing.T) {
+	arr := []interface{}{1.0, 2.0, 3.0}
+	root := map[string]interface{}{"a": arr}
+	if err := MustParse("a[0]").Delete(root); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(arr, []interface{}{1.0, 2.0, 3.0}) {
+		t.Errorf("исходный срез изменен: %v", arr)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	type secret string
+	type inner struct {
+		Port int `json:"port"`
+	}
+	type config struct {
+		Name     string `json:"name"`
+		Password secret `json:"password"`
+		Inner    inner  `json:"inner"`
+	}
+
+	tests := []struct {
+		in   interface{}
+		want interface{}
+	}{
+		{[]int{1, 2}, []interface{}{1, 2}},
+		{map[string]int{"a": 1}, map[string]interface{}{"a": 1}},
+		{&map[string]string{"a": "b"}, map[string]interface{}{"a": "b"}},
+		{(*int)(nil), nil},
+		{
+			config{Name: "x", Password: "s3cr3t", Inner: inner{Port: 80}},
+			map[string]interface{}{"name": "x", "password": "s3cr3t", "inner": map[string]interface{}{"port": 80.0}},
+		},
+		{"text", "text"},
+	}
+	for _, tt := range tests {
+		if got := Normalize(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Normalize(%#v) = %#v, ожидалось %#v", tt.in, got, tt.want)
+		}
+	}
+}
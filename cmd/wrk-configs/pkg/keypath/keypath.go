@@ -0,0 +1,331 @@
+package keypath
+
+// keypath.go
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Язык путей к значениям конфигурации:
+//
+//	database.host            ключи через точку
+//	server.middlewares[1]    индекс массива
+//	server.middlewares[-1]   индекс с конца
+//	logging.outputs.*        все элементы объекта или массива (также [*])
+//	..password               рекурсивный спуск: ключ на любой глубине
+//	paths.conf\.d            экранированная точка внутри ключа
+//	paths["conf.d"]          ключ в кавычках внутри скобок
+
+// Kind вид сегмента пути
+type Kind int
+
+const (
+	KindKey Kind = iota
+	KindIndex
+	KindWildcard
+	KindRecursive
+)
+
+// Segment один шаг пути
+type Segment struct {
+	Kind  Kind
+	Key   string
+	Index int
+}
+
+// Path разобранный путь
+type Path []Segment
+
+// Match найденное значение и его конкретный путь
+type Match struct {
+	Path  string
+	Value interface{}
+}
+
+// Parse разбирает выражение пути
+func Parse(expr string) (Path, error) {
+	var path Path
+	i := 0
+
+	// expectKey сообщает, что после точки ожидается ключ
+	expectKey := true
+
+	for i < len(expr) {
+		switch c := expr[i]; {
+		case c == '.':
+			if i+1 < len(expr) && expr[i+1] == '.' {
+				path = append(path, Segment{Kind: KindRecursive})
+				i += 2
+				expectKey = true
+				continue
+			}
+			if expectKey {
+				return nil, fmt.Errorf("путь %q: пустой ключ в позиции %d", expr, i)
+			}
+			i++
+			expectKey = true
+
+		case c == '[':
+			seg, next, err := parseBracket(expr, i)
+			if err != nil {
+				return nil, err
+			}
+			path = append(path, seg)
+			i = next
+			expectKey = false
+
+		default:
+			if !expectKey {
+				return nil, fmt.Errorf("путь %q: ожидалась точка или [ в позиции %d", expr, i)
+			}
+			key, next := parseKey(expr, i)
+			if key == "*" && next-i == 1 {
+				path = append(path, Segment{Kind: KindWildcard})
+			} else {
+				path = append(path, Segment{Kind: KindKey, Key: key})
+			}
+			i = next
+			expectKey = false
+		}
+	}
+
+	if expectKey && len(path) > 0 {
+		if path[len(path)-1].Kind == KindRecursive {
+			return nil, fmt.Errorf("путь %q: после .. ожидается ключ", expr)
+		}
+		return nil, fmt.Errorf("путь %q: путь не может заканчиваться точкой", expr)
+	}
+
+	return path, nil
+}
+
+// MustParse разбирает путь и паникует при ошибке
+func MustParse(expr string) Path {
+	p, err := Parse(expr)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// parseKey читает ключ до точки или [ с учетом экранирования обратной косой чертой
+func parseKey(expr string, i int) (string, int) {
+	var key strings.Builder
+	for i < len(expr) {
+		c := expr[i]
+		if c == '\\' && i+1 < len(expr) {
+			key.WriteByte(expr[i+1])
+			i += 2
+			continue
+		}
+		if c == '.' || c == '[' {
+			break
+		}
+		key.WriteByte(c)
+		i++
+	}
+	return key.String(), i
+}
+
+// parseBracket читает [n], [-n], [*], ["key"] или ['key']
+func parseBracket(expr string, i int) (Segment, int, error) {
+	end := i + 1
+
+	if end < len(expr) && (expr[end] == '"' || expr[end] == '\'') {
+		quote := expr[end]
+		var key strings.Builder
+		for end++; end < len(expr) && expr[end] != quote; end++ {
+			if expr[end] == '\\' && end+1 < len(expr) {
+				end++
+			}
+			key.WriteByte(expr[end])
+		}
+		if end+1 >= len(expr) || expr[end+1] != ']' {
+			return Segment{}, 0, fmt.Errorf("путь %q: незакрытый ключ в кавычках в позиции %d", expr, i)
+		}
+		return Segment{Kind: KindKey, Key: key.String()}, end + 2, nil
+	}
+
+	close := strings.IndexByte(expr[i:], ']')
+	if close < 0 {
+		return Segment{}, 0, fmt.Errorf("путь %q: незакрытая [ в позиции %d", expr, i)
+	}
+	inner := strings.TrimSpace(expr[i+1 : i+close])
+	next := i + close + 1
+
+	if inner == "*" {
+		return Segment{Kind: KindWildcard}, next, nil
+	}
+	n, err := strconv.Atoi(inner)
+	if err != nil {
+		return Segment{}, 0, fmt.Errorf("путь %q: неверный индекс %q", expr, inner)
+	}
+	return Segment{Kind: KindIndex, Index: n}, next, nil
+}
+
+// String возвращает каноническую запись пути
+func (p Path) String() string {
+	var b strings.Builder
+	for i, seg := range p {
+		switch seg.Kind {
+		case KindKey:
+			if seg.Key == "" {
+				b.WriteString(emptyKey)
+				continue
+			}
+			if i > 0 && p[i-1].Kind != KindRecursive {
+				b.WriteByte('.')
+			}
+			b.WriteString(EscapeKey(seg.Key))
+		case KindIndex:
+			fmt.Fprintf(&b, "[%d]", seg.Index)
+		case KindWildcard:
+			b.WriteString("[*]")
+		case KindRecursive:
+			b.WriteString("..")
+		}
+	}
+	return b.String()
+}
+
+// HasWildcard сообщает, может ли путь вернуть несколько значений
+func (p Path) HasWildcard() bool {
+	for _, seg := range p {
+		if seg.Kind == KindWildcard || seg.Kind == KindRecursive {
+			return true
+		}
+	}
+	return false
+}
+
+// Find возвращает все значения, соответствующие пути, в порядке обхода.
+// Ключи объектов обходятся в отсортированном порядке
+func (p Path) Find(root interface{}) []Match {
+	var matches []Match
+	find(root, "", p, &matches)
+	return matches
+}
+
+func find(node interface{}, prefix string, p Path, matches *[]Match) {
+	if len(p) == 0 {
+		*matches = append(*matches, Match{Path: prefix, Value: node})
+		return
+	}
+
+	seg, rest := p[0], p[1:]
+
+	switch seg.Kind {
+	case KindKey:
+		if obj, ok := node.(map[string]interface{}); ok {
+			if value, exists := obj[seg.Key]; exists {
+				find(value, Join(prefix, seg.Key), rest, matches)
+			}
+		}
+
+	case KindIndex:
+		if arr, ok := node.([]interface{}); ok {
+			i := seg.Index
+			if i < 0 {
+				i += len(arr)
+			}
+			if i >= 0 && i < len(arr) {
+				find(arr[i], Index(prefix, i), rest, matches)
+			}
+		}
+
+	case KindWildcard:
+		eachChild(node, prefix, func(childPath string, child interface{}) {
+			find(child, childPath, rest, matches)
+		})
+
+	case KindRecursive:
+		var descend func(path string, n interface{})
+		descend = func(path string, n interface{}) {
+			find(n, path, rest, matches)
+			eachChild(n, path, descend)
+		}
+		descend(prefix, node)
+	}
+}
+
+// eachChild вызывает fn для всех дочерних значений объекта или массива
+func eachChild(node interface{}, prefix string, fn func(path string, child interface{})) {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fn(Join(prefix, key), v[key])
+		}
+	case []interface{}:
+		for i, item := range v {
+			fn(Index(prefix, i), item)
+		}
+	}
+}
+
+// Query разбирает выражение и возвращает все совпадения
+func Query(root interface{}, expr string) ([]Match, error) {
+	p, err := Parse(expr)
+	if err != nil {
+		return nil, err
+	}
+	return p.Find(root), nil
+}
+
+// Get возвращает значение по пути без шаблонов
+func Get(root interface{}, expr string) (interface{}, bool) {
+	p, err := Parse(expr)
+	if err != nil || p.HasWildcard() {
+		return nil, false
+	}
+	matches := p.Find(root)
+	if len(matches) == 0 {
+		return nil, false
+	}
+	return matches[0].Value, true
+}
+
+// EscapeKey экранирует в ключе символы, имеющие значение в языке путей
+func EscapeKey(key string) string {
+	if key == "*" {
+		return `\*`
+	}
+	if !strings.ContainsAny(key, `.[]\`) {
+		return key
+	}
+
+	var b strings.Builder
+	for _, r := range key {
+		if strings.ContainsRune(`.[]\`, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// emptyKey запись пустого ключа: без кавычек он неотличим от корня
+const emptyKey = `[""]`
+
+// Join добавляет к пути экранированный ключ
+func Join(prefix, key string) string {
+	if key == "" {
+		return prefix + emptyKey
+	}
+	if prefix == "" {
+		return EscapeKey(key)
+	}
+	return prefix + "." + EscapeKey(key)
+}
+
+// Index добавляет к пути индекс массива
+func Index(prefix string, i int) string {
+	return fmt.Sprintf("%s[%d]", prefix, i)
+}
@@ -0,0 +1,157 @@
+package keypath
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		expr string
+		want Path
+	}{
+		{"", nil},
+		{"database.host", Path{{Kind: KindKey, Key: "database"}, {Kind: KindKey, Key: "host"}}},
+		{"server.middlewares[1]", Path{{Kind: KindKey, Key: "server"}, {Kind: KindKey, Key: "middlewares"}, {Kind: KindIndex, Index: 1}}},
+		{"items[-1]", Path{{Kind: KindKey, Key: "items"}, {Kind: KindIndex, Index: -1}}},
+		{"logging.outputs.*", Path{{Kind: KindKey, Key: "logging"}, {Kind: KindKey, Key: "outputs"}, {Kind: KindWildcard}}},
+		{"a[*]", Path{{Kind: KindKey, Key: "a"}, {Kind: KindWildcard}}},
+		{"..password", Path{{Kind: KindRecursive}, {Kind: KindKey, Key: "password"}}},
+		{"a..b", Path{{Kind: KindKey, Key: "a"}, {Kind: KindRecursive}, {Kind: KindKey, Key: "b"}}},
+		{`paths.conf\.d`, Path{{Kind: KindKey, Key: "paths"}, {Kind: KindKey, Key: "conf.d"}}},
+		{`paths["conf.d"]`, Path{{Kind: KindKey, Key: "paths"}, {Kind: KindKey, Key: "conf.d"}}},
+		{`paths['a]b']`, Path{{Kind: KindKey, Key: "paths"}, {Kind: KindKey, Key: "a]b"}}},
+		{`\*`, Path{{Kind: KindKey, Key: "*"}}},
+		{"[0][1]", Path{{Kind: KindIndex, Index: 0}, {Kind: KindIndex, Index: 1}}},
+		{`[""]`, Path{{Kind: KindKey, Key: ""}}},
+		{`a[""].b`, Path{{Kind: KindKey, Key: "a"}, {Kind: KindKey, Key: ""}, {Kind: KindKey, Key: "b"}}},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.expr)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.expr, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Parse(%q) = %+v, ожидалось %+v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, expr := range []string{
+		".a", "a.", "a..", "a..b.", "a.[0]x", "a[x]", "a[1", `a["b]`, "a[0]b",
+	} {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("Parse(%q): ожидалась ошибка", expr)
+		}
+	}
+}
+
+func TestStringRoundTrip(t *testing.T) {
+	for _, expr := range []string{
+		"database.host", "server.middlewares[1]", "items[-1]", "a[*]", "..password",
+		"a..b", `paths.conf\.d`, `weird\[key\]`, `\*`, `back\\slash`,
+		`[""]`, `a[""]`, `a[""].b`, `..[""]`,
+	} {
+		p := MustParse(expr)
+		again, err := Parse(p.String())
+		if err != nil {
+			t.Errorf("%q: Parse(%q): %v", expr, p.String(), err)
+			continue
+		}
+		if !reflect.DeepEqual(p, again) {
+			t.Errorf("%q: %+v после String %q стал %+v", expr, p, p.String(), again)
+		}
+	}
+}
+
+func TestFind(t *testing.T) {
+	root := decode(t, `{
+		"database": {"host": "localhost", "password": "a"},
+		"servers": [{"name": "x", "port": 1}, {"name": "y", "port": 2}],
+		"nested": {"deep": {"password": "b"}},
+		"paths": {"conf.d": "/etc"}
+	}`)
+
+	tests := []struct {
+		expr  string
+		paths []string
+	}{
+		{"database.host", []string{"database.host"}},
+		{"servers[1].name", []string{"servers[1].name"}},
+		{"servers[-1].port", []string{"servers[1].port"}},
+		{"servers[2]", nil},
+		{"servers[-3]", nil},
+		{"servers.*.name", []string{"servers[0].name", "servers[1].name"}},
+		{"database.*", []string{"database.host", "database.password"}},
+		{"..password", []string{"database.password", "nested.deep.password"}},
+		{`paths["conf.d"]`, []string{`paths.conf\.d`}},
+		{"database.host.x", nil},
+		{"missing", nil},
+	}
+	for _, tt := range tests {
+		matches, err := Query(root, tt.expr)
+		if err != nil {
+			t.Errorf("Query(%q): %v", tt.expr, err)
+			continue
+		}
+		var paths []string
+		for _, m := range matches {
+			paths = append(paths, m.Path)
+		}
+		if !reflect.DeepEqual(paths, tt.paths) {
+			t.Errorf("Query(%q) = %q, ожидалось %q", tt.expr, paths, tt.paths)
+		}
+	}
+}
+
+func TestGet(t *testing.T) {
+	root := decode(t, `{"a": {"b": [10, 20]}}`)
+
+	if value, ok := Get(root, "a.b[-1]"); !ok || value != 20.0 {
+		t.Errorf("Get(a.b[-1]) = %v, %v", value, ok)
+	}
+	if _, ok := Get(root, "a.b[*]"); ok {
+		t.Error("Get с шаблоном должен возвращать false")
+	}
+	if _, ok := Get(root, "a.c"); ok {
+		t.Error("Get отсутствующего ключа должен возвращать false")
+	}
+}
+
+func TestJoinAndIndex(t *testing.T) {
+	if got := Join("", "a.b"); got != `a\.b` {
+		t.Errorf("Join = %q", got)
+	}
+	if got := Index(Join("x", "*"), 2); got != `x.\*[2]` {
+		t.Errorf("Index = %q", got)
+	}
+
+	// Пустой ключ отличается от корня
+	tests := []struct{ prefix, key, want string }{
+		{"", "", `[""]`},
+		{"a", "", `a[""]`},
+		{Join("", ""), "b", `[""].b`},
+	}
+	for _, tt := range tests {
+		got := Join(tt.prefix, tt.key)
+		if got != tt.want {
+			t.Errorf("Join(%q, %q) = %q, ожидалось %q", tt.prefix, tt.key, got, tt.want)
+		}
+		if value, ok := Get(map[string]interface{}{"": map[string]interface{}{"b": 1}, "a": map[string]interface{}{"": 2}}, got); !ok || value == nil {
+			t.Errorf("Get(%q) = %v, %v", got, value, ok)
+		}
+	}
+}
+
+// decode разбирает JSON-объект
+func decode(t *testing.T, data string) map[string]interface{} {
+	t.Helper()
+	var result map[string]interface{}
+	if err := json.Unmarshal([]byte(data), &result); err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
@@ -0,0 +1,271 @@
+package manager
+
+// manager.go
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/decode"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/document"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/generators"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/include"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/interpolate"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/parsers"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/profile"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/secrets"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/types"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/utils"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/validation"
+)
+
+// ConfigManager реализует types.ConfigManager поверх реестров
+// parsers и generators, поэтому поддерживает все зарегистрированные форматы
+type ConfigManager struct {
+	// Interpolator, если задан, раскрывает подстановки ${...}
+	// после разбора файла в Load и LoadDynamic
+	Interpolator *interpolate.Interpolator
+	// Includes, если задан, раскрывает директивы $include в Load и LoadDynamic
+	Includes *include.Loader
+	// Profiles, если задан, накладывает профиль (APP_ENV, блок profiles,
+	// файлы app.<профиль>.yml) в Load и LoadDynamic до раскрытия подстановок
+	Profiles *profile.Loader
+	// Secrets, если задан, расшифровывает значения ENC[...] в Load и LoadDynamic
+	// после раскрытия подстановок
+	Secrets *secrets.Cipher
+}
+
+var _ types.ConfigManager = (*ConfigManager)(nil)
+
+// NewConfigManager создает новый менеджер конфигураций
+func NewConfigManager() *ConfigManager {
+	return &ConfigManager{}
+}
+
+// Load загружает файл любого поддерживаемого формата в v.
+// Если v - структура, она проверяется по тегам validate
+func (m *ConfigManager) Load(path string, v interface{}) error {
+	if m.Interpolator == nil && m.Includes == nil && m.Profiles == nil && m.Secrets == nil {
+		if err := parsers.LoadFile(path, v); err != nil {
+			return err
+		}
+	} else {
+		// Включения, профили, подстановки и секреты раскрываются в динамическом виде, затем значение декодируется в v
+		data, _, err := m.LoadDynamic(path)
+		if err != nil {
+			return err
+		}
+		if err := decode.Decode(data, v); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	if err := validation.Struct(v); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// LoadDynamic загружает файл в map[string]interface{} и возвращает его формат
+func (m *ConfigManager) LoadDynamic(path string) (map[string]interface{}, types.ConfigFormat, error) {
+	data, format, err := m.loadDynamic(path)
+	if err != nil {
+		return nil, format, err
+	}
+	if m.Interpolator != nil {
+		if err := m.Interpolator.Apply(data); err != nil {
+			return nil, format, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	if m.Secrets != nil {
+		if err := m.Secrets.Apply(data); err != nil {
+			return nil, format, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return data, format, nil
+}
+
+// loadDynamic читает файл, раскрывая включения и накладывая профиль, если они включены
+func (m *ConfigManager) loadDynamic(path string) (map[string]interface{}, types.ConfigFormat, error) {
+	if m.Includes == nil && m.Profiles == nil {
+		return parsers.LoadDynamicFile(path)
+	}
+
+	format, err := m.FormatOf(path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	load := func(path string) (map[string]interface{}, error) {
+		if m.Includes == nil {
+			data, _, err := parsers.LoadDynamicFile(path)
+			return data, err
+		}
+		result, err := m.Includes.LoadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return result.Data, nil
+	}
+
+	if m.Profiles == nil {
+		data, err := load(path)
+		return data, format, err
+	}
+
+	profiles := *m.Profiles
+	if profiles.Load == nil {
+		profiles.Load = load
+	}
+	result, err := profiles.LoadFile(path)
+	if err != nil {
+		return nil, format, err
+	}
+	return result.Data, format, nil
+}
+
+// Save сохраняет v в файл. Если файл уже существует, сохраняется его формат
+// (определенный по расширению или содержимому), иначе формат берется из расширения
+func (m *ConfigManager) Save(path string, v interface{}) error {
+	format, err := m.FormatOf(path)
+	if err != nil {
+		return err
+	}
+	return m.SaveAs(path, v, format)
+}
+
+// SaveAs сохраняет v в файл в указанном формате.
+// Если файл уже существует в том же формате YAML, INI или TOML,
+// в него переносятся только измененные значения: комментарии,
+// порядок ключей и пустые строки сохраняются (см. пакет document)
+func (m *ConfigManager) SaveAs(path string, v interface{}, format types.ConfigFormat) error {
+	data, err := generators.Generate(format, v)
+	if err != nil {
+		return fmt.Errorf("не удалось сгенерировать %s для %s: %w", format, path, err)
+	}
+
+	if edited, ok := m.rewrite(path, data, format); ok {
+		data = edited
+	}
+	return writeFile(path, data)
+}
+
+// rewrite применяет сгенерированные данные к существующему файлу с сохранением
+// разметки. Если это невозможно (файла нет, формат другой, удалены ключи),
+// возвращает false, и файл перезаписывается целиком
+func (m *ConfigManager) rewrite(path string, generated []byte, format types.ConfigFormat) ([]byte, bool) {
+	if !document.Supports(format) {
+		return nil, false
+	}
+
+	existing, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	if current, err := parsers.DetectFormat(path, existing); err != nil || current != format {
+		return nil, false
+	}
+
+	value, err := parsers.ParseDynamic(format, generated)
+	if err != nil {
+		return nil, false
+	}
+
+	edited, err := document.Rewrite(format, existing, value)
+	if err != nil {
+		return nil, false
+	}
+	return edited, true
+}
+
+// Validate проверяет, что файл существует, его формат поддерживается
+// и содержимое разбирается без ошибок
+func (m *ConfigManager) Validate(path string) error {
+	_, _, err := m.LoadDynamic(path)
+	return err
+}
+
+// Convert конвертирует srcPath в dstPath. Если dstFormat пуст,
+// формат определяется по расширению dstPath
+func (m *ConfigManager) Convert(srcPath, dstPath string, dstFormat types.ConfigFormat) error {
+	if dstFormat == "" {
+		dstFormat = utils.GetFormatByExtension(filepath.Ext(dstPath))
+		if dstFormat == "" {
+			return &types.UnsupportedFormatError{Path: dstPath}
+		}
+	}
+
+	data, err := os.ReadFile(srcPath)
+	if err != nil {
+		return fmt.Errorf("не удалось прочитать файл %s: %w", srcPath, err)
+	}
+
+	srcFormat, err := parsers.DetectFormat(srcPath, data)
+	if err != nil {
+		return err
+	}
+
+	out, err := m.ConvertData(data, srcFormat, dstFormat)
+	if err != nil {
+		return fmt.Errorf("не удалось конвертировать %s в %s: %w", srcPath, dstPath, err)
+	}
+
+	return writeFile(dstPath, out)
+}
+
+// ConvertData конвертирует данные из одного формата в другой через map[string]interface{}
+func (m *ConfigManager) ConvertData(data []byte, srcFormat, dstFormat types.ConfigFormat) ([]byte, error) {
+	value, err := parsers.ParseDynamic(srcFormat, data)
+	if err != nil {
+		return nil, err
+	}
+	return generators.Generate(dstFormat, value)
+}
+
+// FormatOf определяет формат, в котором нужно сохранять файл path
+func (m *ConfigManager) FormatOf(path string) (types.ConfigFormat, error) {
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		return parsers.DetectFormat(path, data)
+	case os.IsNotExist(err):
+		if format := utils.GetFormatByExtension(filepath.Ext(path)); format != "" {
+			return format, nil
+		}
+		return "", &types.UnsupportedFormatError{Path: path}
+	default:
+		return "", fmt.Errorf("не удалось прочитать файл %s: %w", path, err)
+	}
+}
+
+// writeFile атомарно записывает данные: сначала во временный файл
+// в той же директории, затем переименовывает его, сохраняя права доступа
+func writeFile(path string, data []byte) error {
+	perm := os.FileMode(0o644)
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("не удалось создать временный файл для %s: %w", path, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("не удалось записать файл %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("не удалось записать файл %s: %w", path, err)
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return fmt.Errorf("не удалось установить права файла %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("не удалось сохранить файл %s: %w", path, err)
+	}
+
+	return nil
+}
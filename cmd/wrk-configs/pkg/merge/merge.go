@@ -0,0 +1,318 @@
+package merge
+
+// merge.go
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/decode"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/keypath"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/utils"
+)
+
+// ArrayStrategy способ слияния массивов
+type ArrayStrategy int
+
+const (
+	// ArrayReplace массив источника с большим приоритетом заменяет прежний
+	ArrayReplace ArrayStrategy = iota
+	// ArrayAppend элементы добавляются в конец прежнего массива
+	ArrayAppend
+	// ArrayMergeByKey объекты с одинаковым значением ключа сливаются,
+	// остальные элементы добавляются в конец
+	ArrayMergeByKey
+)
+
+// ArrayRule правило слияния массивов; Key используется для ArrayMergeByKey
+type ArrayRule struct {
+	Strategy ArrayStrategy
+	Key      string
+}
+
+// layer источник с приоритетом
+type layer struct {
+	source   Source
+	priority int
+	order    int
+}
+
+// Merger глубоко сливает несколько источников конфигурации.
+// Источники применяются по возрастанию приоритета: значения источника
+// с большим приоритетом перекрывают прежние, объекты сливаются рекурсивно.
+// Строки нетипизированных источников (UntypedSource: INI, окружение, флаги)
+// приводятся к типу перекрываемого значения
+type Merger struct {
+	// Arrays правило слияния массивов по умолчанию
+	Arrays ArrayRule
+	// ArrayRules правила для отдельных путей, например "server.middlewares"
+	ArrayRules map[string]ArrayRule
+
+	layers []layer
+}
+
+// NewMerger создает Merger с заменой массивов по умолчанию
+func NewMerger() *Merger {
+	return &Merger{ArrayRules: make(map[string]ArrayRule)}
+}
+
+// Add добавляет источник с приоритетом выше всех добавленных ранее
+func (m *Merger) Add(source Source) *Merger {
+	priority := 0
+	for _, l := range m.layers {
+		if l.priority >= priority {
+			priority = l.priority + 1
+		}
+	}
+	return m.AddWithPriority(source, priority)
+}
+
+// AddWithPriority добавляет источник с явным приоритетом.
+// При равных приоритетах побеждает источник, добавленный позже
+func (m *Merger) AddWithPriority(source Source, priority int) *Merger {
+	m.layers = append(m.layers, layer{source: source, priority: priority, order: len(m.layers)})
+	return m
+}
+
+// Result результат слияния с информацией о происхождении ключей
+type Result struct {
+	Data       map[string]interface{}
+	provenance map[string]string
+}
+
+// Source возвращает имя источника, задавшего значение по пути
+// ("server.port", "server.middlewares[1]")
+func (r *Result) Source(path string) (string, bool) {
+	name, ok := r.provenance[path]
+	return name, ok
+}
+
+// Provenance возвращает пути листовых значений и их источники в порядке путей
+func (r *Result) Provenance() []string {
+	paths := make([]string, 0, len(r.provenance))
+	for path := range r.provenance {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	lines := make([]string, len(paths))
+	for i, path := range paths {
+		lines[i] = path + " <- " + r.provenance[path]
+	}
+	return lines
+}
+
+// Decode записывает результат в структуру декодером с приведением типов
+// (теги json, yaml, toml и ini), поэтому строки INI ложатся в числовые поля
+func (r *Result) Decode(v interface{}) error {
+	return decode.Decode(r.Data, v)
+}
+
+// Merge загружает все источники и сливает их
+func (m *Merger) Merge() (*Result, error) {
+	layers := append([]layer(nil), m.layers...)
+	sort.SliceStable(layers, func(i, j int) bool {
+		if layers[i].priority != layers[j].priority {
+			return layers[i].priority < layers[j].priority
+		}
+		return layers[i].order < layers[j].order
+	})
+
+	result := &Result{
+		Data:       make(map[string]interface{}),
+		provenance: make(map[string]string),
+	}
+
+	for _, l := range layers {
+		var (
+			data map[string]interface{}
+			err  error
+		)
+		if overlay, ok := l.source.(OverlaySource); ok {
+			data, err = overlay.Overlay(result.Data)
+		} else {
+			data, err = l.source.Load()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("источник %s: %w", l.source.Name(), err)
+		}
+
+		untyped := false
+		if u, ok := l.source.(UntypedSource); ok {
+			untyped = u.Untyped()
+		}
+		m.mergeMap(result, result.Data, data, "", l.source.Name(), untyped)
+	}
+
+	return result, nil
+}
+
+// MergeInto сливает источники и записывает результат в структуру
+func (m *Merger) MergeInto(v interface{}) (*Result, error) {
+	result, err := m.Merge()
+	if err != nil {
+		return nil, err
+	}
+	return result, result.Decode(v)
+}
+
+// mergeMap сливает src в dst
+func (m *Merger) mergeMap(r *Result, dst, src map[string]interface{}, prefix, source string, untyped bool) {
+	for key, value := range src {
+		path := keypath.Join(prefix, key)
+
+		srcMap, srcIsMap := value.(map[string]interface{})
+		dstMap, dstIsMap := dst[key].(map[string]interface{})
+		if srcIsMap && dstIsMap {
+			m.mergeMap(r, dstMap, srcMap, path, source, untyped)
+			continue
+		}
+
+		srcArr, srcIsArr := value.([]interface{})
+		dstArr, dstIsArr := dst[key].([]interface{})
+		if srcIsArr && dstIsArr {
+			dst[key] = m.mergeArray(r, dstArr, srcArr, path, source, untyped)
+			continue
+		}
+
+		r.forget(path)
+		value = utils.DeepCopy(value)
+		if untyped {
+			value = coerce(value, dst[key])
+		}
+		dst[key] = value
+		r.record(path, value, source)
+	}
+}
+
+// coerce приводит строку нетипизированного источника к типу значения,
+// которое она перекрывает, как это делает env.Overlay. Если строка не
+// разбирается как прежний тип, она остается строкой
+func coerce(value, previous interface{}) interface{} {
+	s, ok := value.(string)
+	if !ok {
+		return value
+	}
+	switch previous.(type) {
+	case nil, string, map[string]interface{}:
+		return value
+	}
+	parsed, err := utils.ParseLike(s, previous)
+	if err != nil {
+		return value
+	}
+	return parsed
+}
+
+// mergeArray сливает массивы согласно правилу для пути
+func (m *Merger) mergeArray(r *Result, dst, src []interface{}, path, source string, untyped bool) []interface{} {
+	rule := m.Arrays
+	if pathRule, ok := m.ArrayRules[path]; ok {
+		rule = pathRule
+	}
+
+	switch rule.Strategy {
+	case ArrayAppend:
+		for _, item := range src {
+			dst = append(dst, utils.DeepCopy(item))
+			r.record(keypath.Index(path, len(dst)-1), dst[len(dst)-1], source)
+		}
+		return dst
+
+	case ArrayMergeByKey:
+		for _, item := range src {
+			if i := findByKey(dst, item, rule.Key); i >= 0 {
+				itemPath := keypath.Index(path, i)
+				if srcMap, ok := item.(map[string]interface{}); ok {
+					m.mergeMap(r, dst[i].(map[string]interface{}), srcMap, itemPath, source, untyped)
+				}
+				continue
+			}
+			dst = append(dst, utils.DeepCopy(item))
+			r.record(keypath.Index(path, len(dst)-1), dst[len(dst)-1], source)
+		}
+		return dst
+
+	default:
+		r.forget(path)
+		result := utils.DeepCopy(src).([]interface{})
+		r.record(path, result, source)
+		return result
+	}
+}
+
+// findByKey ищет элемент с тем же значением ключа (для объектов)
+// или равный элемент (для скаляров)
+func findByKey(arr []interface{}, item interface{}, key string) int {
+	itemMap, isMap := item.(map[string]interface{})
+
+	for i, existing := range arr {
+		if !isMap {
+			if equalValues(existing, item) {
+				return i
+			}
+			continue
+		}
+
+		existingMap, ok := existing.(map[string]interface{})
+		if !ok || key == "" {
+			continue
+		}
+		a, aok := existingMap[key]
+		b, bok := itemMap[key]
+		if aok && bok && equalValues(a, b) {
+			return i
+		}
+	}
+	return -1
+}
+
+// record запоминает источник для пути и всех вложенных значений
+func (r *Result) record(path string, value interface{}, source string) {
+	r.provenance[path] = source
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			r.record(keypath.Join(path, key), item, source)
+		}
+	case []interface{}:
+		for i, item := range v {
+			r.record(keypath.Index(path, i), item, source)
+		}
+	}
+}
+
+// forget удаляет сведения о происхождении пути и вложенных в него путей
+func (r *Result) forget(path string) {
+	for existing := range r.provenance {
+		if existing == path || strings.HasPrefix(existing, path+".") || strings.HasPrefix(existing, path+"[") {
+			delete(r.provenance, existing)
+		}
+	}
+}
+
+// equalValues сравнивает значения, приводя числа разных типов к float64
+func equalValues(a, b interface{}) bool {
+	if na, ok := toFloat(a); ok {
+		nb, ok := toFloat(b)
+		return ok && na == nb
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,262 @@
+package merge
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMergePrecedence(t *testing.T) {
+	defaults := map[string]interface{}{
+		"server": map[string]interface{}{"host": "0.0.0.0", "port": 8080, "tls": map[string]interface{}{"on": false}},
+		"name":   "app",
+	}
+	file := map[string]interface{}{
+		"server": map[string]interface{}{"port": 9090, "tls": map[string]interface{}{"cert": "a.pem"}},
+	}
+	override := map[string]interface{}{
+		"server": map[string]interface{}{"host": "localhost"},
+		"name":   nil,
+	}
+
+	result, err := NewMerger().
+		Add(MapSource("defaults", defaults)).
+		Add(MapSource("file", file)).
+		Add(MapSource("override", override)).
+		Merge()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]interface{}{
+		"server": map[string]interface{}{
+			"host": "localhost",
+			"port": 9090,
+			"tls":  map[string]interface{}{"on": false, "cert": "a.pem"},
+		},
+		"name": nil,
+	}
+	if !reflect.DeepEqual(result.Data, want) {
+		t.Errorf("получено %v", result.Data)
+	}
+
+	sources := map[string]string{
+		"server.host":     "override",
+		"server.port":     "file",
+		"server.tls.on":   "defaults",
+		"server.tls.cert": "file",
+		"name":            "override",
+	}
+	for path, want := range sources {
+		if got, ok := result.Source(path); !ok || got != want {
+			t.Errorf("Source(%q) = %q, %v, ожидалось %q", path, got, ok, want)
+		}
+	}
+
+	// Источники не изменяются слиянием
+	if defaults["server"].(map[string]interface{})["host"] != "0.0.0.0" {
+		t.Error("слияние изменило исходную map")
+	}
+}
+
+func TestMergePriority(t *testing.T) {
+	result, err := NewMerger().
+		AddWithPriority(MapSource("high", map[string]interface{}{"a": 1}), 10).
+		AddWithPriority(MapSource("low", map[string]interface{}{"a": 2}), 1).
+		AddWithPriority(MapSource("same", map[string]interface{}{"b": 1}), 5).
+		AddWithPriority(MapSource("later", map[string]interface{}{"b": 2}), 5).
+		Merge()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Data["a"] != 1 || result.Data["b"] != 2 {
+		t.Errorf("получено %v", result.Data)
+	}
+}
+
+func TestMergeArrays(t *testing.T) {
+	base := map[string]interface{}{
+		"list":    []interface{}{1, 2},
+		"servers": []interface{}{map[string]interface{}{"name": "a", "port": 1}, map[string]interface{}{"name": "b", "port": 2}},
+	}
+	over := map[string]interface{}{
+		"list":    []interface{}{2, 3},
+		"servers": []interface{}{map[string]interface{}{"name": "b", "port": 20}, map[string]interface{}{"name": "c", "port": 3}},
+	}
+
+	tests := []struct {
+		name    string
+		rule    ArrayRule
+		rules   map[string]ArrayRule
+		list    []interface{}
+		servers []interface{}
+	}{
+		{
+			name:    "замена",
+			list:    []interface{}{2, 3},
+			servers: over["servers"].([]interface{}),
+		},
+		{
+			name: "добавление",
+			rule: ArrayRule{Strategy: ArrayAppend},
+			list: []interface{}{1, 2, 2, 3},
+			servers: []interface{}{
+				map[string]interface{}{"name": "a", "port": 1}, map[string]interface{}{"name": "b", "port": 2},
+				map[string]interface{}{"name": "b", "port": 20}, map[string]interface{}{"name": "c", "port": 3},
+			},
+		},
+		{
+			name:  "слияние по ключу для пути",
+			rules: map[string]ArrayRule{"servers": {Strategy: ArrayMergeByKey, Key: "name"}, "list": {Strategy: ArrayMergeByKey}},
+			list:  []interface{}{1, 2, 3},
+			servers: []interface{}{
+				map[string]interface{}{"name": "a", "port": 1}, map[string]interface{}{"name": "b", "port": 20},
+				map[string]interface{}{"name": "c", "port": 3},
+			},
+		},
+	}
+	for _, tt := range tests {
+		m := NewMerger()
+		m.Arrays = tt.rule
+		for path, rule := range tt.rules {
+			m.ArrayRules[path] = rule
+		}
+		result, err := m.Add(MapSource("base", base)).Add(MapSource("over", over)).Merge()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(result.Data["list"], tt.list) {
+			t.Errorf("%s: list = %v, ожидалось %v", tt.name, result.Data["list"], tt.list)
+		}
+		if !reflect.DeepEqual(result.Data["servers"], tt.servers) {
+			t.Errorf("%s: servers = %v, ожидалось %v", tt.name, result.Data["servers"], tt.servers)
+		}
+	}
+}
+
+func TestMergeProvenanceReplaced(t *testing.T) {
+	result, err := NewMerger().
+		Add(MapSource("a", map[string]interface{}{"db": map[string]interface{}{"host": "x", "port": 1}})).
+		Add(MapSource("b", map[string]interface{}{"db": "dsn"})).
+		Merge()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"db <- b"}
+	if got := result.Provenance(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Provenance = %q, ожидалось %q", got, want)
+	}
+}
+
+func TestMergeIntoStruct(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.json")
+	if err := os.WriteFile(path, []byte(`{"server": {"port": 9090}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var cfg struct {
+		Server struct {
+			Host string `json:"host"`
+			Port int    `json:"port"`
+		} `json:"server"`
+	}
+	_, err := NewMerger().
+		Add(MapSource("defaults", map[string]interface{}{"server": map[string]interface{}{"host": "localhost", "port": 8080}})).
+		Add(FileSource(path)).
+		MergeInto(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Server.Host != "localhost" || cfg.Server.Port != 9090 {
+		t.Errorf("получено %+v", cfg)
+	}
+
+	if _, err := NewMerger().Add(FileSource(filepath.Join(dir, "missing.json"))).Merge(); err == nil {
+		t.Error("нет файла: ожидалась ошибка")
+	}
+}
+
+func TestFlagSource(t *testing.T) {
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.String("server.port", "", "")
+	flags.String("server.host", "", "")
+	flags.String("debug", "", "")
+	if err := flags.Parse([]string{"-server.port=9090", "-debug=true"}); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := NewMerger().
+		Add(MapSource("defaults", map[string]interface{}{"server": map[string]interface{}{"port": 8080, "host": "x"}})).
+		Add(FlagSource(flags)).
+		Merge()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"server": map[string]interface{}{"port": 9090, "host": "x"},
+		"debug":  "true",
+	}
+	if !reflect.DeepEqual(result.Data, want) {
+		t.Errorf("получено %#v", result.Data)
+	}
+
+	bad := flag.NewFlagSet("test", flag.ContinueOnError)
+	bad.String("server.port", "", "")
+	_ = bad.Parse([]string{"-server.port=abc"})
+	if _, err := NewMerger().
+		Add(MapSource("defaults", map[string]interface{}{"server": map[string]interface{}{"port": 8080}})).
+		Add(FlagSource(bad)).
+		Merge(); err == nil {
+		t.Error("флаг не приводится к типу: ожидалась ошибка")
+	}
+}
+
+func TestMergeCoercesUntypedSources(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, data string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	base := write("base.json", `{"server": {"port": 8080, "debug": false, "version": 1.5, "name": "x"}}`)
+	ini := write("over.ini", "[server]\nport = 9090\ndebug = true\nname = 42\n")
+	yaml := write("over.yaml", "server:\n  version: \"1.0\"\n  debug: \"true\"\n")
+
+	result, err := NewMerger().Add(FileSource(base)).Add(FileSource(ini)).Merge()
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := result.Data["server"].(map[string]interface{})
+	if server["port"] != 9090.0 || server["debug"] != true || server["name"] != "42" {
+		t.Errorf("INI: получено %#v", server)
+	}
+
+	// Строки типизированного формата остаются строками
+	result, err = NewMerger().Add(FileSource(base)).Add(FileSource(yaml)).Merge()
+	if err != nil {
+		t.Fatal(err)
+	}
+	server = result.Data["server"].(map[string]interface{})
+	if server["version"] != "1.0" || server["debug"] != "true" {
+		t.Errorf("YAML: получено %#v", server)
+	}
+}
+
+func TestMergeProvenanceEscapesKeys(t *testing.T) {
+	result, err := NewMerger().
+		Add(MapSource("dotted", map[string]interface{}{"a.b": 1})).
+		Add(MapSource("nested", map[string]interface{}{"a": map[string]interface{}{"b": 2}})).
+		Merge()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"a <- nested", "a.b <- nested", `a\.b <- dotted`}
+	if got := result.Provenance(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Provenance = %q, ожидалось %q", got, want)
+	}
+}
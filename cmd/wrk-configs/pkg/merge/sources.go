@@ -0,0 +1,186 @@
+package merge
+
+// sources.go
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/env"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/keypath"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/parsers"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/types"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/utils"
+)
+
+// Source источник конфигурации для слияния
+type Source interface {
+	// Name имя источника для отчета о происхождении ключей
+	Name() string
+	// Load возвращает значения источника
+	Load() (map[string]interface{}, error)
+}
+
+// OverlaySource источник, значения которого зависят от уже собранной
+// конфигурации (например, переменные окружения приводятся к типам базовых ключей)
+type OverlaySource interface {
+	Source
+	Overlay(base map[string]interface{}) (map[string]interface{}, error)
+}
+
+// UntypedSource источник, значения которого не типизированы (все значения -
+// строки). Его строки приводятся к типу перекрываемых значений
+type UntypedSource interface {
+	Source
+	// Untyped сообщает, что значения последней загрузки не типизированы
+	Untyped() bool
+}
+
+// fileSource файл любого поддерживаемого формата
+type fileSource struct {
+	path   string
+	format types.ConfigFormat
+}
+
+// FileSource создает источник из файла JSON, YAML, INI или TOML
+func FileSource(path string) Source {
+	return &fileSource{path: path}
+}
+
+func (s *fileSource) Name() string {
+	return s.path
+}
+
+func (s *fileSource) Load() (map[string]interface{}, error) {
+	data, format, err := parsers.LoadDynamicFile(s.path)
+	s.format = format
+	return data, err
+}
+
+// Untyped сообщает, что файл в формате INI
+func (s *fileSource) Untyped() bool {
+	return s.format == types.FormatINI
+}
+
+// mapSource готовая map, например значения по умолчанию
+type mapSource struct {
+	name string
+	data map[string]interface{}
+}
+
+// MapSource создает источник из map[string]interface{}
+func MapSource(name string, data map[string]interface{}) Source {
+	return &mapSource{name: name, data: data}
+}
+
+func (s *mapSource) Name() string {
+	return s.name
+}
+
+func (s *mapSource) Load() (map[string]interface{}, error) {
+	return utils.DeepCopy(s.data).(map[string]interface{}), nil
+}
+
+// envSource переменные окружения через env.Overlay
+type envSource struct {
+	overlay *env.Overlay
+}
+
+// EnvSource создает источник из переменных окружения.
+// Переменные сопоставляются с ключами уже собранной конфигурации
+func EnvSource(overlay *env.Overlay) Source {
+	return &envSource{overlay: overlay}
+}
+
+func (s *envSource) Name() string {
+	if s.overlay.Prefix == "" {
+		return "env"
+	}
+	return "env:" + s.overlay.Prefix
+}
+
+// Untyped: значения новых ключей (Overlay.AllowNew) остаются строками
+func (s *envSource) Untyped() bool {
+	return true
+}
+
+func (s *envSource) Load() (map[string]interface{}, error) {
+	return s.Overlay(map[string]interface{}{})
+}
+
+func (s *envSource) Overlay(base map[string]interface{}) (map[string]interface{}, error) {
+	working := utils.DeepCopy(base).(map[string]interface{})
+	report, err := s.overlay.ApplyMap(working)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]interface{})
+	for _, key := range report.Keys() {
+		value, _ := keypath.Get(working, key)
+		if err := keypath.MustParse(key).Set(result, value); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// flagSource флаги командной строки с именами-путями ("server.port")
+type flagSource struct {
+	flags *flag.FlagSet
+}
+
+// FlagSource создает источник из флагов, явно заданных в командной строке.
+// Имя флага - путь keypath ("server.port"); значение приводится к типу базового ключа
+func FlagSource(flags *flag.FlagSet) Source {
+	return &flagSource{flags: flags}
+}
+
+func (s *flagSource) Name() string {
+	return "flags"
+}
+
+// Untyped: строковые флаги новых ключей остаются строками
+func (s *flagSource) Untyped() bool {
+	return true
+}
+
+func (s *flagSource) Load() (map[string]interface{}, error) {
+	return s.Overlay(map[string]interface{}{})
+}
+
+func (s *flagSource) Overlay(base map[string]interface{}) (map[string]interface{}, error) {
+	result := make(map[string]interface{})
+	var err error
+
+	s.flags.Visit(func(f *flag.Flag) {
+		if err != nil {
+			return
+		}
+
+		var value interface{} = f.Value.String()
+		if getter, ok := f.Value.(flag.Getter); ok {
+			value = getter.Get()
+		}
+
+		path, parseErr := keypath.Parse(f.Name)
+		if parseErr != nil {
+			err = fmt.Errorf("флаг -%s: %w", f.Name, parseErr)
+			return
+		}
+
+		if existing, exists := keypath.Get(base, f.Name); exists {
+			parsed, parseErr := utils.ParseLike(fmt.Sprint(value), existing)
+			if parseErr != nil {
+				err = fmt.Errorf("флаг -%s: %w", f.Name, parseErr)
+				return
+			}
+			value = parsed
+		}
+		if setErr := path.Set(result, value); setErr != nil {
+			err = fmt.Errorf("флаг -%s: %w", f.Name, setErr)
+		}
+	})
+
+	return result, err
+}
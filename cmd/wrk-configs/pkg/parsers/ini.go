@@ -2,12 +2,18 @@
 package parsers
 
 import (
+	"strings"
+
 	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/types"
 	"gopkg.in/ini.v1"
 )
 
 type INIParser struct{}
 
+func init() {
+	Register(NewINIParser())
+}
+
 func NewINIParser() *INIParser {
 	return &INIParser{}
 }
@@ -31,3 +37,49 @@ func (p *INIParser) ParseFile(path string, v interface{}) error {
 func (p *INIParser) Format() types.ConfigFormat {
 	return types.FormatINI
 }
+
+// ParseDynamic парсит INI в map[string]interface{} для динамического доступа.
+// Ключи секции по умолчанию попадают в корень, секции становятся вложенными
+// объектами, а имена вида "server.limits" - объектами на нескольких уровнях.
+// Значения INI не типизированы и всегда возвращаются строками
+func (p *INIParser) ParseDynamic(data []byte) (map[string]interface{}, error) {
+	cfg, err := ini.Load(data)
+	if err != nil {
+		return nil, err
+	}
+	return iniToMap(cfg), nil
+}
+
+// ParseDynamicFile парсит INI файл в map[string]interface{}
+func (p *INIParser) ParseDynamicFile(path string) (map[string]interface{}, error) {
+	cfg, err := ini.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	return iniToMap(cfg), nil
+}
+
+// iniToMap преобразует загруженный INI файл в дерево map[string]interface{}
+func iniToMap(cfg *ini.File) map[string]interface{} {
+	result := make(map[string]interface{})
+
+	for _, section := range cfg.Sections() {
+		target := result
+		if section.Name() != ini.DefaultSection {
+			for _, part := range strings.Split(section.Name(), ".") {
+				next, ok := target[part].(map[string]interface{})
+				if !ok {
+					next = make(map[string]interface{})
+					target[part] = next
+				}
+				target = next
+			}
+		}
+
+		for _, key := range section.Keys() {
+			target[key.Name()] = key.String()
+		}
+	}
+
+	return result
+}
@@ -11,6 +11,10 @@ import (
 
 type JSONParser struct{}
 
+func init() {
+	Register(NewJSONParser())
+}
+
 func NewJSONParser() *JSONParser {
 	return &JSONParser{}
 }
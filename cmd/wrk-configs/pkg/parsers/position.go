@@ -0,0 +1,319 @@
+package parsers
+
+// position.go
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/keypath"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/types"
+	"gopkg.in/yaml.v3"
+)
+
+// KeyPositions возвращает позиции ключей в исходных данных.
+// Ключи записываются через точку ("database.host"), элементы массивов -
+// индексом в квадратных скобках ("server.middlewares[1]").
+// Для неизвестного формата возвращается пустая карта без ошибки
+func KeyPositions(format types.ConfigFormat, data []byte) (map[string]types.Position, error) {
+	switch format {
+	case types.FormatJSON:
+		return jsonPositions(data)
+	case types.FormatYAML:
+		return yamlPositions(data)
+	case types.FormatINI:
+		return iniPositions(data), nil
+	case types.FormatTOML:
+		return tomlPositions(data), nil
+	default:
+		return map[string]types.Position{}, nil
+	}
+}
+
+// LookupPosition ищет позицию пути, а если ее нет - позицию ближайшего предка
+func LookupPosition(positions map[string]types.Position, path string) types.Position {
+	p, err := keypath.Parse(path)
+	if err != nil {
+		return types.Position{}
+	}
+	for ; len(p) > 0; p = p[:len(p)-1] {
+		if pos, ok := positions[p.String()]; ok {
+			return pos
+		}
+	}
+	return types.Position{}
+}
+
+// lineIndex переводит смещение в данных в строку и столбец
+type lineIndex []int
+
+func newLineIndex(data []byte) lineIndex {
+	starts := lineIndex{0}
+	for i, b := range data {
+		if b == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+	return starts
+}
+
+func (li lineIndex) position(offset int) types.Position {
+	line := sort.Search(len(li), func(i int) bool { return li[i] > offset }) - 1
+	return types.Position{Line: line + 1, Column: offset - li[line] + 1}
+}
+
+// jsonPositions обходит JSON токенами и запоминает смещения ключей и элементов
+func jsonPositions(data []byte) (map[string]types.Position, error) {
+	positions := make(map[string]types.Position)
+	lines := newLineIndex(data)
+	decoder := json.NewDecoder(bytes.NewReader(data))
+
+	// start возвращает смещение следующего токена, пропуская разделители
+	start := func() int {
+		offset := int(decoder.InputOffset())
+		for offset < len(data) && strings.IndexByte(" \t\r\n,:", data[offset]) >= 0 {
+			offset++
+		}
+		return offset
+	}
+
+	var walk func(path string) error
+	walk = func(path string) error {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+
+		delim, ok := token.(json.Delim)
+		if !ok {
+			return nil
+		}
+
+		switch delim {
+		case '{':
+			for decoder.More() {
+				offset := start()
+				keyToken, err := decoder.Token()
+				if err != nil {
+					return err
+				}
+				key := keypath.Join(path, fmt.Sprint(keyToken))
+				positions[key] = lines.position(offset)
+				if err := walk(key); err != nil {
+					return err
+				}
+			}
+		case '[':
+			for i := 0; decoder.More(); i++ {
+				key := keypath.Index(path, i)
+				positions[key] = lines.position(start())
+				if err := walk(key); err != nil {
+					return err
+				}
+			}
+		}
+
+		// Закрывающая скобка
+		_, err = decoder.Token()
+		return err
+	}
+
+	if err := walk(""); err != nil {
+		return nil, err
+	}
+	return positions, nil
+}
+
+// yamlPositions использует позиции узлов yaml.Node
+func yamlPositions(data []byte) (map[string]types.Position, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+
+	positions := make(map[string]types.Position)
+
+	var walk func(node *yaml.Node, path string)
+	walk = func(node *yaml.Node, path string) {
+		switch node.Kind {
+		case yaml.DocumentNode:
+			for _, child := range node.Content {
+				walk(child, path)
+			}
+		case yaml.AliasNode:
+			if node.Alias != nil {
+				walk(node.Alias, path)
+			}
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				keyNode := node.Content[i]
+				key := keypath.Join(path, keyNode.Value)
+				positions[key] = types.Position{Line: keyNode.Line, Column: keyNode.Column}
+				walk(node.Content[i+1], key)
+			}
+		case yaml.SequenceNode:
+			for i, item := range node.Content {
+				key := keypath.Index(path, i)
+				positions[key] = types.Position{Line: item.Line, Column: item.Column}
+				walk(item, key)
+			}
+		}
+	}
+
+	walk(&root, "")
+	return positions, nil
+}
+
+// iniPositions построчно находит секции и ключи INI
+func iniPositions(data []byte) map[string]types.Position {
+	positions := make(map[string]types.Position)
+	section := ""
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		trimmed := strings.TrimSpace(text)
+		column := strings.Index(text, trimmed) + 1
+
+		switch {
+		case trimmed == "" || trimmed[0] == ';' || trimmed[0] == '#':
+		case trimmed[0] == '[':
+			end := strings.Index(trimmed, "]")
+			if end < 0 {
+				continue
+			}
+			name := strings.TrimSpace(trimmed[1:end])
+			if name == "DEFAULT" {
+				section = ""
+				continue
+			}
+			section = ""
+			for _, part := range strings.Split(name, ".") {
+				section = keypath.Join(section, part)
+			}
+			positions[section] = types.Position{Line: line, Column: column + 1}
+		default:
+			end := strings.IndexAny(trimmed, "=:")
+			if end <= 0 {
+				continue
+			}
+			key := strings.Trim(strings.TrimSpace(trimmed[:end]), "\"`")
+			positions[keypath.Join(section, key)] = types.Position{Line: line, Column: column}
+		}
+	}
+
+	return positions
+}
+
+// tomlPositions построчно находит таблицы, массивы таблиц и ключи TOML.
+// Ключи внутри inline-таблиц не индексируются и получают позицию родителя
+func tomlPositions(data []byte) map[string]types.Position {
+	positions := make(map[string]types.Position)
+	tableCounts := make(map[string]int)
+	table := ""
+	skipUntil := ""
+	depth := 0
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+
+		// Продолжение многострочной строки
+		if skipUntil != "" {
+			if strings.Contains(text, skipUntil) {
+				skipUntil = ""
+			}
+			continue
+		}
+
+		// Продолжение многострочного массива
+		if depth > 0 {
+			depth += bracketDepth(text)
+			continue
+		}
+
+		trimmed := strings.TrimSpace(text)
+		column := strings.Index(text, trimmed) + 1
+
+		switch {
+		case trimmed == "" || trimmed[0] == '#':
+		case strings.HasPrefix(trimmed, "[["):
+			end := strings.Index(trimmed, "]]")
+			if end < 0 {
+				continue
+			}
+			name := tomlKey("", trimmed[2:end])
+			index := tableCounts[name]
+			tableCounts[name] = index + 1
+			table = keypath.Index(name, index)
+			positions[table] = types.Position{Line: line, Column: column + 2}
+			if index == 0 {
+				positions[name] = types.Position{Line: line, Column: column + 2}
+			}
+		case trimmed[0] == '[':
+			end := strings.Index(trimmed, "]")
+			if end < 0 {
+				continue
+			}
+			table = tomlKey("", trimmed[1:end])
+			positions[table] = types.Position{Line: line, Column: column + 1}
+		default:
+			eq := strings.Index(trimmed, "=")
+			if eq <= 0 {
+				continue
+			}
+			key := tomlKey(table, trimmed[:eq])
+			positions[key] = types.Position{Line: line, Column: column}
+
+			value := strings.TrimSpace(trimmed[eq+1:])
+			for _, quote := range []string{`"""`, `'''`} {
+				if strings.HasPrefix(value, quote) && !strings.Contains(value[len(quote):], quote) {
+					skipUntil = quote
+				}
+			}
+			if skipUntil == "" && strings.HasPrefix(value, "[") {
+				depth = bracketDepth(value)
+			}
+		}
+	}
+
+	return positions
+}
+
+// tomlKey добавляет к пути prefix ключ TOML, убирая пробелы вокруг точек и кавычки
+func tomlKey(prefix, raw string) string {
+	path := prefix
+	for _, part := range strings.Split(raw, ".") {
+		path = keypath.Join(path, strings.Trim(strings.TrimSpace(part), `"'`))
+	}
+	return path
+}
+
+// bracketDepth считает баланс квадратных скобок вне строк и комментариев
+func bracketDepth(text string) int {
+	depth := 0
+	var quote rune
+
+	for _, r := range text {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '#':
+			return depth
+		case r == '[':
+			depth++
+		case r == ']':
+			depth--
+		}
+	}
+
+	return depth
+}
@@ -0,0 +1,200 @@
+package parsers
+
+// registry.go
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/types"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/utils"
+)
+
+// DynamicParser парсер, умеющий разбирать данные в map[string]interface{}
+type DynamicParser interface {
+	types.Parser
+	ParseDynamic(data []byte) (map[string]interface{}, error)
+}
+
+// ExtensionParser парсер формата, расширения файлов которого
+// неизвестны utils.GetFormatByExtension (например, сторонний формат)
+type ExtensionParser interface {
+	types.Parser
+	Extensions() []string
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[types.ConfigFormat]types.Parser)
+	extensions = make(map[string]types.ConfigFormat)
+)
+
+// Register регистрирует парсер для его формата.
+// Предназначен для вызова из init(); повторная регистрация формата
+// или пустой формат приводят к панике, как в database/sql.Register
+func Register(p types.Parser) {
+	if p == nil {
+		panic("parsers: Register с nil парсером")
+	}
+
+	format := p.Format()
+	if format == "" {
+		panic("parsers: Register с пустым форматом")
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, exists := registry[format]; exists {
+		panic(fmt.Sprintf("parsers: парсер для формата %s уже зарегистрирован", format))
+	}
+	registry[format] = p
+
+	if ep, ok := p.(ExtensionParser); ok {
+		for _, ext := range ep.Extensions() {
+			extensions[strings.ToLower(ext)] = format
+		}
+	}
+}
+
+// Get возвращает зарегистрированный парсер для формата
+func Get(format types.ConfigFormat) (types.Parser, error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	p, exists := registry[format]
+	if !exists {
+		return nil, &types.UnsupportedFormatError{Format: format}
+	}
+	return p, nil
+}
+
+// Formats возвращает отсортированный список зарегистрированных форматов
+func Formats() []types.ConfigFormat {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	formats := make([]types.ConfigFormat, 0, len(registry))
+	for format := range registry {
+		formats = append(formats, format)
+	}
+	sort.Slice(formats, func(i, j int) bool { return formats[i] < formats[j] })
+	return formats
+}
+
+// ParseFormat приводит имя формата из командной строки ("yaml", "YML", ".toml")
+// к types.ConfigFormat; синонимы берутся из расширений файлов.
+// Наличие парсера или генератора для формата проверяет вызывающий код
+func ParseFormat(name string) types.ConfigFormat {
+	ext := "." + strings.ToLower(strings.TrimPrefix(name, "."))
+
+	registryMu.RLock()
+	format, exists := extensions[ext]
+	registryMu.RUnlock()
+	if exists {
+		return format
+	}
+
+	if format := utils.GetFormatByExtension(ext); format != "" {
+		return format
+	}
+	return types.ConfigFormat(ext[1:])
+}
+
+// DetectFormat определяет формат по расширению файла
+// (сначала среди расширений зарегистрированных парсеров),
+// а если расширение неизвестно - по содержимому
+func DetectFormat(path string, data []byte) (types.ConfigFormat, error) {
+	registryMu.RLock()
+	format, exists := extensions[strings.ToLower(filepath.Ext(path))]
+	registryMu.RUnlock()
+	if exists {
+		return format, nil
+	}
+
+	if format := utils.GetFormatByExtension(filepath.Ext(path)); format != "" {
+		return format, nil
+	}
+	if format := utils.GetFormatByContent(data); format != "" {
+		return format, nil
+	}
+	return "", &types.UnsupportedFormatError{Path: path}
+}
+
+// Parse разбирает данные указанного формата в v.
+// Если v имеет тип *map[string]interface{} и парсер реализует DynamicParser,
+// используется ParseDynamic, поэтому динамическая загрузка работает и для INI
+func Parse(format types.ConfigFormat, data []byte, v interface{}) error {
+	p, err := Get(format)
+	if err != nil {
+		return err
+	}
+
+	if m, ok := v.(*map[string]interface{}); ok {
+		if dp, ok := p.(DynamicParser); ok {
+			result, err := dp.ParseDynamic(data)
+			if err != nil {
+				return err
+			}
+			*m = result
+			return nil
+		}
+	}
+
+	return p.Parse(data, v)
+}
+
+// ParseDynamic разбирает данные указанного формата в map[string]interface{}
+func ParseDynamic(format types.ConfigFormat, data []byte) (map[string]interface{}, error) {
+	var result map[string]interface{}
+	if err := Parse(format, data, &result); err != nil {
+		return nil, err
+	}
+	if result == nil {
+		result = make(map[string]interface{})
+	}
+	return result, nil
+}
+
+// LoadFile читает файл, определяет его формат и разбирает содержимое в v
+func LoadFile(path string, v interface{}) error {
+	_, err := loadFile(path, v)
+	return err
+}
+
+// LoadDynamicFile читает файл любого зарегистрированного формата
+// в map[string]interface{} и возвращает определенный формат
+func LoadDynamicFile(path string) (map[string]interface{}, types.ConfigFormat, error) {
+	var result map[string]interface{}
+	format, err := loadFile(path, &result)
+	if err != nil {
+		return nil, format, err
+	}
+	if result == nil {
+		result = make(map[string]interface{})
+	}
+	return result, format, nil
+}
+
+// loadFile общая часть LoadFile и LoadDynamicFile
+func loadFile(path string, v interface{}) (types.ConfigFormat, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("не удалось прочитать файл %s: %w", path, err)
+	}
+
+	format, err := DetectFormat(path, data)
+	if err != nil {
+		return "", err
+	}
+
+	if err := Parse(format, data, v); err != nil {
+		return format, fmt.Errorf("не удалось распарсить %s из %s: %w", format, path, err)
+	}
+
+	return format, nil
+}
@@ -0,0 +1,56 @@
+package parsers
+
+// toml.go
+
+import (
+	"os"
+
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/types"
+	"github.com/pelletier/go-toml/v2"
+)
+
+type TOMLParser struct{}
+
+func init() {
+	Register(NewTOMLParser())
+}
+
+func NewTOMLParser() *TOMLParser {
+	return &TOMLParser{}
+}
+
+func (p *TOMLParser) Parse(data []byte, v interface{}) error {
+	return toml.Unmarshal(data, v)
+}
+
+func (p *TOMLParser) ParseFile(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return p.Parse(data, v)
+}
+
+func (p *TOMLParser) Format() types.ConfigFormat {
+	return types.FormatTOML
+}
+
+// ParseDynamic парсит TOML в map[string]interface{} для динамического доступа.
+// Таблицы и inline-таблицы становятся map[string]interface{},
+// массивы таблиц - []interface{} из map[string]interface{},
+// целые числа - int64, даты со смещением - time.Time,
+// локальные даты и время - toml.LocalDate, toml.LocalTime, toml.LocalDateTime.
+func (p *TOMLParser) ParseDynamic(data []byte) (map[string]interface{}, error) {
+	result := make(map[string]interface{})
+	err := toml.Unmarshal(data, &result)
+	return result, err
+}
+
+// ParseDynamicFile парсит TOML файл в map[string]interface{}
+func (p *TOMLParser) ParseDynamicFile(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return p.ParseDynamic(data)
+}
@@ -3,6 +3,7 @@ package parsers
 // yaml.go
 
 import (
+	"fmt"
 	"os"
 
 	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/types"
@@ -11,6 +12,10 @@ import (
 
 type YAMLParser struct{}
 
+func init() {
+	Register(NewYAMLParser())
+}
+
 func NewYAMLParser() *YAMLParser {
 	return &YAMLParser{}
 }
@@ -30,3 +35,49 @@ func (p *YAMLParser) ParseFile(path string, v interface{}) error {
 func (p *YAMLParser) Format() types.ConfigFormat {
 	return types.FormatYAML
 }
+
+// ParseDynamic парсит YAML в map[string]interface{} для динамического доступа.
+// Вложенные отображения с нестроковыми ключами приводятся к map[string]interface{}
+func (p *YAMLParser) ParseDynamic(data []byte) (map[string]interface{}, error) {
+	var result map[string]interface{}
+	if err := yaml.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	if result == nil {
+		result = make(map[string]interface{})
+	}
+	return normalizeYAML(result).(map[string]interface{}), nil
+}
+
+// ParseDynamicFile парсит YAML файл в map[string]interface{}
+func (p *YAMLParser) ParseDynamicFile(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return p.ParseDynamic(data)
+}
+
+// normalizeYAML рекурсивно заменяет map[interface{}]interface{} на map[string]interface{}
+func normalizeYAML(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = normalizeYAML(item)
+		}
+		return v
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result[fmt.Sprint(key)] = normalizeYAML(item)
+		}
+		return result
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalizeYAML(item)
+		}
+		return v
+	default:
+		return v
+	}
+}
@@ -0,0 +1,368 @@
+package patch
+
+// apply.go
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/utils"
+)
+
+// ErrTestFailed операция test обнаружила другое значение
+var ErrTestFailed = errors.New("проверка не прошла")
+
+// OperationError ошибка операции патча; Index - номер операции с 0
+type OperationError struct {
+	Index int
+	Op    Operation
+	Err   error
+}
+
+func (e *OperationError) Error() string {
+	return fmt.Sprintf("операция %d (%s %s): %v", e.Index, e.Op.Op, e.Op.Path, e.Err)
+}
+
+func (e *OperationError) Unwrap() error {
+	return e.Err
+}
+
+// Decode разбирает документ JSON Patch и проверяет обязательные поля операций.
+// Целые числа декодируются как int64, остальные - как float64
+func Decode(data []byte) (Patch, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var raw []map[string]interface{}
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("JSON Patch должен быть массивом операций: %w", err)
+	}
+
+	p := make(Patch, len(raw))
+	for i, fields := range raw {
+		op, err := decodeOperation(fields)
+		if err != nil {
+			return nil, fmt.Errorf("операция %d: %w", i, err)
+		}
+		p[i] = op
+	}
+	return p, nil
+}
+
+func decodeOperation(fields map[string]interface{}) (Operation, error) {
+	var op Operation
+
+	name, ok := fields["op"].(string)
+	if !ok {
+		return op, errors.New("нет строкового поля op")
+	}
+	path, ok := fields["path"].(string)
+	if !ok {
+		return op, errors.New("нет строкового поля path")
+	}
+	op.Op, op.Path = name, path
+
+	switch name {
+	case OpAdd, OpReplace, OpTest:
+		value, exists := fields["value"]
+		if !exists {
+			return op, fmt.Errorf("%s: нет поля value", name)
+		}
+		op.Value = numbers(value)
+	case OpMove, OpCopy:
+		from, ok := fields["from"].(string)
+		if !ok {
+			return op, fmt.Errorf("%s: нет строкового поля from", name)
+		}
+		op.From = from
+	case OpRemove:
+	default:
+		return op, fmt.Errorf("неизвестная операция %q", name)
+	}
+	return op, nil
+}
+
+// numbers заменяет json.Number на int64 или float64
+func numbers(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = numbers(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = numbers(item)
+		}
+	}
+	return value
+}
+
+// Applier применяет JSON Patch к динамической конфигурации
+type Applier struct {
+	// Loose сравнивает в test строку со значением другого типа по смыслу
+	// ("8080" равно 8080). Нужен для INI, где все значения - строки
+	Loose bool
+}
+
+// NewApplier создает Applier со строгим сравнением в test
+func NewApplier() *Applier {
+	return &Applier{}
+}
+
+// Apply применяет патч строгим Applier
+func (p Patch) Apply(doc map[string]interface{}) (map[string]interface{}, error) {
+	return NewApplier().Apply(p, doc)
+}
+
+// Apply применяет операции по порядку к копии doc и возвращает результат.
+// Если любая операция (в том числе test) не выполнена, возвращается
+// *OperationError, а doc не изменяется
+func (a *Applier) Apply(p Patch, doc map[string]interface{}) (map[string]interface{}, error) {
+	var root interface{} = utils.DeepCopy(doc)
+	for i, op := range p {
+		var err error
+		if root, err = a.apply(op, root); err != nil {
+			return nil, &OperationError{Index: i, Op: op, Err: err}
+		}
+	}
+
+	result, ok := root.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("результат патча должен быть объектом, получено %T", root)
+	}
+	return result, nil
+}
+
+// apply выполняет одну операцию и возвращает новый корень
+func (a *Applier) apply(op Operation, root interface{}) (interface{}, error) {
+	path, err := ParsePointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case OpAdd:
+		return add(root, path, utils.DeepCopy(op.Value))
+	case OpRemove:
+		return remove(root, path)
+	case OpReplace:
+		if _, err := get(root, path); err != nil {
+			return nil, err
+		}
+		return replace(root, path, utils.DeepCopy(op.Value))
+	case OpTest:
+		value, err := get(root, path)
+		if err != nil {
+			return nil, err
+		}
+		if !a.equal(value, op.Value) {
+			return nil, fmt.Errorf("%w: ожидалось %s, получено %s", ErrTestFailed, canonical(op.Value), canonical(value))
+		}
+		return root, nil
+	case OpMove, OpCopy:
+		from, err := ParsePointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+		value, err := get(root, from)
+		if err != nil {
+			return nil, fmt.Errorf("from: %w", err)
+		}
+		if op.Op == OpCopy {
+			return add(root, path, utils.DeepCopy(value))
+		}
+		if op.From == op.Path {
+			return root, nil
+		}
+		if strings.HasPrefix(op.Path, op.From+"/") {
+			return nil, errors.New("нельзя переместить значение внутрь самого себя")
+		}
+		if root, err = remove(root, from); err != nil {
+			return nil, err
+		}
+		return add(root, path, value)
+	default:
+		return nil, fmt.Errorf("неизвестная операция %q", op.Op)
+	}
+}
+
+// get возвращает значение по токенам указателя
+func get(node interface{}, tokens []string) (interface{}, error) {
+	for _, token := range tokens {
+		child, err := childOf(node, token)
+		if err != nil {
+			return nil, err
+		}
+		node = child
+	}
+	return node, nil
+}
+
+// add добавляет ключ объекта или вставляет элемент массива ("-" - в конец)
+func add(root interface{}, tokens []string, value interface{}) (interface{}, error) {
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	return modify(root, tokens, func(parent interface{}, token string) (interface{}, error) {
+		switch p := parent.(type) {
+		case map[string]interface{}:
+			p[token] = value
+			return p, nil
+		case []interface{}:
+			if token == "-" {
+				return append(p, value), nil
+			}
+			i, err := arrayIndex(token, len(p)+1)
+			if err != nil {
+				return nil, err
+			}
+			p = append(p, nil)
+			copy(p[i+1:], p[i:])
+			p[i] = value
+			return p, nil
+		default:
+			return nil, fmt.Errorf("значение %T не является объектом или массивом", parent)
+		}
+	})
+}
+
+// remove удаляет существующий ключ или элемент массива
+func remove(root interface{}, tokens []string) (interface{}, error) {
+	if len(tokens) == 0 {
+		return nil, errors.New("нельзя удалить весь документ")
+	}
+	return modify(root, tokens, func(parent interface{}, token string) (interface{}, error) {
+		if _, err := childOf(parent, token); err != nil {
+			return nil, err
+		}
+		switch p := parent.(type) {
+		case map[string]interface{}:
+			delete(p, token)
+			return p, nil
+		default:
+			arr := parent.([]interface{})
+			i, _ := arrayIndex(token, len(arr))
+			return append(arr[:i], arr[i+1:]...), nil
+		}
+	})
+}
+
+// replace заменяет существующее значение
+func replace(root interface{}, tokens []string, value interface{}) (interface{}, error) {
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	return modify(root, tokens, func(parent interface{}, token string) (interface{}, error) {
+		return setChild(parent, token, value), nil
+	})
+}
+
+// modify спускается к родителю последнего токена, вызывает fn
+// и записывает измененного родителя обратно (массивы могут перераспределяться)
+func modify(node interface{}, tokens []string, fn func(parent interface{}, token string) (interface{}, error)) (interface{}, error) {
+	if len(tokens) == 1 {
+		return fn(node, tokens[0])
+	}
+
+	child, err := childOf(node, tokens[0])
+	if err != nil {
+		return nil, err
+	}
+	updated, err := modify(child, tokens[1:], fn)
+	if err != nil {
+		return nil, err
+	}
+	return setChild(node, tokens[0], updated), nil
+}
+
+// childOf возвращает существующий дочерний элемент
+func childOf(node interface{}, token string) (interface{}, error) {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		value, exists := n[token]
+		if !exists {
+			return nil, fmt.Errorf("ключ %q не найден", token)
+		}
+		return value, nil
+	case []interface{}:
+		i, err := arrayIndex(token, len(n))
+		if err != nil {
+			return nil, err
+		}
+		return n[i], nil
+	default:
+		return nil, fmt.Errorf("значение %T не является объектом или массивом", node)
+	}
+}
+
+// setChild записывает дочерний элемент, существование которого уже проверено
+func setChild(node interface{}, token string, value interface{}) interface{} {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		n[token] = value
+	case []interface{}:
+		i, _ := arrayIndex(token, len(n))
+		n[i] = value
+	}
+	return node
+}
+
+// arrayIndex разбирает индекс массива по RFC 6901: без знака и ведущих нулей, меньше limit
+func arrayIndex(token string, limit int) (int, error) {
+	if token == "" || (len(token) > 1 && token[0] == '0') || strings.TrimLeft(token, "0123456789") != "" {
+		return 0, fmt.Errorf("неверный индекс массива %q", token)
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil || i >= limit {
+		return 0, fmt.Errorf("индекс %s вне массива", token)
+	}
+	return i, nil
+}
+
+// equal сравнивает значения как JSON: числа разных типов равны по значению
+func (a *Applier) equal(actual, expected interface{}) bool {
+	if reflect.DeepEqual(actual, expected) || canonical(actual) == canonical(expected) {
+		return true
+	}
+	if !a.Loose {
+		return false
+	}
+
+	s, ok := actual.(string)
+	if !ok {
+		return false
+	}
+	switch expected.(type) {
+	case nil, string, map[string]interface{}:
+		return false
+	case []interface{}:
+		items := utils.SplitList(s)
+		list := make([]interface{}, len(items))
+		for i, item := range items {
+			list[i] = item
+		}
+		return a.equal(list, expected)
+	}
+	converted, err := utils.ParseLike(s, expected)
+	return err == nil && canonical(converted) == canonical(expected)
+}
+
+// canonical возвращает JSON-представление значения
+func canonical(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(data)
+}
@@ -0,0 +1,241 @@
+package patch
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// rfc6902Tests примеры из приложения A RFC 6902 и дополнительные случаи.
+// want пустой, если ожидается ошибка
+var rfc6902Tests = []struct {
+	name  string
+	doc   string
+	patch string
+	want  string
+}{
+	{"A.1 добавление члена объекта",
+		`{"foo":"bar"}`,
+		`[{"op":"add","path":"/baz","value":"qux"}]`,
+		`{"baz":"qux","foo":"bar"}`},
+	{"A.2 добавление элемента массива",
+		`{"foo":["bar","baz"]}`,
+		`[{"op":"add","path":"/foo/1","value":"qux"}]`,
+		`{"foo":["bar","qux","baz"]}`},
+	{"A.3 удаление члена объекта",
+		`{"baz":"qux","foo":"bar"}`,
+		`[{"op":"remove","path":"/baz"}]`,
+		`{"foo":"bar"}`},
+	{"A.4 удаление элемента массива",
+		`{"foo":["bar","qux","baz"]}`,
+		`[{"op":"remove","path":"/foo/1"}]`,
+		`{"foo":["bar","baz"]}`},
+	{"A.5 замена значения",
+		`{"baz":"qux","foo":"bar"}`,
+		`[{"op":"replace","path":"/baz","value":"boo"}]`,
+		`{"baz":"boo","foo":"bar"}`},
+	{"A.6 перемещение значения",
+		`{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`,
+		`[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`,
+		`{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+	{"A.7 перемещение элемента массива",
+		`{"foo":["all","grass","cows","eat"]}`,
+		`[{"op":"move","from":"/foo/1","path":"/foo/3"}]`,
+		`{"foo":["all","cows","eat","grass"]}`},
+	{"A.8 успешный test",
+		`{"baz":"qux","foo":["a",2,"c"]}`,
+		`[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2}]`,
+		`{"baz":"qux","foo":["a",2,"c"]}`},
+	{"A.9 неуспешный test",
+		`{"baz":"qux"}`,
+		`[{"op":"test","path":"/baz","value":"bar"}]`,
+		``},
+	{"A.10 добавление вложенного объекта",
+		`{"foo":"bar"}`,
+		`[{"op":"add","path":"/child","value":{"grandchild":{}}}]`,
+		`{"foo":"bar","child":{"grandchild":{}}}`},
+	{"A.11 неизвестные поля операции игнорируются",
+		`{"foo":"bar"}`,
+		`[{"op":"add","path":"/baz","value":"qux","xyz":123}]`,
+		`{"foo":"bar","baz":"qux"}`},
+	{"A.12 добавление в несуществующий объект",
+		`{"foo":"bar"}`,
+		`[{"op":"add","path":"/baz/bat","value":"qux"}]`,
+		``},
+	{"A.14 порядок раскодирования ~",
+		`{"/":9,"~1":10}`,
+		`[{"op":"test","path":"/~01","value":10}]`,
+		`{"/":9,"~1":10}`},
+	{"A.15 строка не равна числу",
+		`{"/":9,"~1":10}`,
+		`[{"op":"test","path":"/~01","value":"10"}]`,
+		``},
+	{"A.16 добавление массива в конец массива",
+		`{"foo":["bar"]}`,
+		`[{"op":"add","path":"/foo/-","value":["abc","def"]}]`,
+		`{"foo":["bar",["abc","def"]]}`},
+
+	{"копирование",
+		`{"a":{"b":1}}`,
+		`[{"op":"copy","from":"/a","path":"/c"}]`,
+		`{"a":{"b":1},"c":{"b":1}}`},
+	{"замена всего документа",
+		`{"a":1}`,
+		`[{"op":"replace","path":"","value":{"b":2}}]`,
+		`{"b":2}`},
+	{"test сравнивает объекты без учета порядка ключей",
+		`{"a":{"x":1,"y":[1,2]}}`,
+		`[{"op":"test","path":"/a","value":{"y":[1,2],"x":1.0}}]`,
+		`{"a":{"x":1,"y":[1,2]}}`},
+	{"перемещение в собственного потомка",
+		`{"a":{"b":{}}}`,
+		`[{"op":"move","from":"/a","path":"/a/b/c"}]`,
+		``},
+	{"индекс с ведущим нулем",
+		`{"a":[1,2]}`,
+		`[{"op":"replace","path":"/a/01","value":3}]`,
+		``},
+	{"индекс за концом массива",
+		`{"a":[1,2]}`,
+		`[{"op":"add","path":"/a/3","value":3}]`,
+		``},
+	{"добавление по индексу длины массива",
+		`{"a":[1,2]}`,
+		`[{"op":"add","path":"/a/2","value":3}]`,
+		`{"a":[1,2,3]}`},
+	{"удаление по -",
+		`{"a":[1,2]}`,
+		`[{"op":"remove","path":"/a/-"}]`,
+		``},
+	{"замена отсутствующего ключа",
+		`{"a":1}`,
+		`[{"op":"replace","path":"/b","value":2}]`,
+		``},
+	{"удаление отсутствующего ключа",
+		`{"a":1}`,
+		`[{"op":"remove","path":"/b"}]`,
+		``},
+}
+
+func TestApplyRFC6902(t *testing.T) {
+	for _, tt := range rfc6902Tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := decodeJSON(t, tt.doc)
+			p, err := Decode([]byte(tt.patch))
+			if err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+
+			got, err := p.Apply(doc)
+			if tt.want == "" {
+				if err == nil {
+					t.Fatalf("ожидалась ошибка, получено %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Apply: %v", err)
+			}
+			if !reflect.DeepEqual(normalize(t, got), decodeJSON(t, tt.want)) {
+				t.Errorf("получено %s, ожидалось %s", encodeJSON(t, got), tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyIsAtomic(t *testing.T) {
+	doc := decodeJSON(t, `{"a":1,"list":[1,2]}`)
+	p, err := Decode([]byte(`[
+		{"op":"replace","path":"/a","value":2},
+		{"op":"remove","path":"/list/0"},
+		{"op":"test","path":"/a","value":3}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = p.Apply(doc)
+	var opErr *OperationError
+	if !errors.As(err, &opErr) || opErr.Index != 2 || !errors.Is(err, ErrTestFailed) {
+		t.Fatalf("ожидалась ошибка test в операции 2, получено %v", err)
+	}
+	if !reflect.DeepEqual(doc, decodeJSON(t, `{"a":1,"list":[1,2]}`)) {
+		t.Errorf("исходный документ изменен: %v", doc)
+	}
+}
+
+func TestApplyLoose(t *testing.T) {
+	doc := map[string]interface{}{"server": map[string]interface{}{"port": "8080", "hosts": "a, b"}}
+	p, err := Decode([]byte(`[
+		{"op":"test","path":"/server/port","value":8080},
+		{"op":"test","path":"/server/hosts","value":["a","b"]}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := p.Apply(doc); err == nil {
+		t.Error("строгий test не должен считать \"8080\" равным 8080")
+	}
+	applier := NewApplier()
+	applier.Loose = true
+	if _, err := applier.Apply(p, doc); err != nil {
+		t.Errorf("нестрогий test: %v", err)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []string{
+		`{"op":"add"}`,
+		`[{"path":"/a","value":1}]`,
+		`[{"op":"add","path":"/a"}]`,
+		`[{"op":"move","path":"/a"}]`,
+		`[{"op":"merge","path":"/a","value":1}]`,
+	}
+	for _, data := range tests {
+		if _, err := Decode([]byte(data)); err == nil {
+			t.Errorf("Decode(%s): ожидалась ошибка", data)
+		}
+	}
+}
+
+func TestDecodeNullValue(t *testing.T) {
+	p, err := Decode([]byte(`[{"op":"add","path":"/a","value":null}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := p.Apply(map[string]interface{}{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, exists := got["a"]; !exists || value != nil {
+		t.Errorf("ожидался ключ a со значением null, получено %v", got)
+	}
+}
+
+// decodeJSON разбирает JSON-объект
+func decodeJSON(t *testing.T, data string) map[string]interface{} {
+	t.Helper()
+	var result map[string]interface{}
+	if err := json.Unmarshal([]byte(data), &result); err != nil {
+		t.Fatalf("%s: %v", data, err)
+	}
+	return result
+}
+
+// encodeJSON записывает значение в JSON для сообщений
+func encodeJSON(t *testing.T, v interface{}) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// normalize приводит числа к float64, как после json.Unmarshal
+func normalize(t *testing.T, v map[string]interface{}) map[string]interface{} {
+	t.Helper()
+	return decodeJSON(t, encodeJSON(t, v))
+}
@@ -0,0 +1,38 @@
+package patch
+
+// mergepatch.go
+
+import "github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/utils"
+
+// MergePatch применяет JSON Merge Patch (RFC 7386) к копии doc:
+// объекты сливаются рекурсивно, null удаляет ключ, остальные значения
+// (включая массивы) заменяются целиком. doc не изменяется.
+// Патч может быть загружен из любого формата; в TOML и INI нет null,
+// поэтому удалять ключи можно только патчами JSON и YAML
+func MergePatch(doc, patch map[string]interface{}) map[string]interface{} {
+	result := utils.DeepCopy(doc).(map[string]interface{})
+	mergeObject(result, patch)
+	return result
+}
+
+// mergeObject сливает patch в target
+func mergeObject(target, patch map[string]interface{}) {
+	for key, value := range patch {
+		if value == nil {
+			delete(target, key)
+			continue
+		}
+
+		if patchObj, ok := value.(map[string]interface{}); ok {
+			targetObj, ok := target[key].(map[string]interface{})
+			if !ok {
+				targetObj = make(map[string]interface{})
+			}
+			mergeObject(targetObj, patchObj)
+			target[key] = targetObj
+			continue
+		}
+
+		target[key] = utils.DeepCopy(value)
+	}
+}
@@ -0,0 +1,43 @@
+package patch
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestMergePatchRFC7386 примеры из приложения A RFC 7386
+func TestMergePatchRFC7386(t *testing.T) {
+	tests := []struct {
+		doc, patch, want string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+	for _, tt := range tests {
+		doc := decodeJSON(t, tt.doc)
+		got := MergePatch(doc, decodeJSON(t, tt.patch))
+		if !reflect.DeepEqual(got, decodeJSON(t, tt.want)) {
+			t.Errorf("%s + %s = %s, ожидалось %s", tt.doc, tt.patch, encodeJSON(t, got), tt.want)
+		}
+		if !reflect.DeepEqual(doc, decodeJSON(t, tt.doc)) {
+			t.Errorf("%s + %s: исходный документ изменен", tt.doc, tt.patch)
+		}
+	}
+}
+
+func TestMergePatchDoesNotSharePatchValues(t *testing.T) {
+	mp := decodeJSON(t, `{"a":{"b":[1]}}`)
+	got := MergePatch(map[string]interface{}{}, mp)
+	got["a"].(map[string]interface{})["b"] = "changed"
+	if !reflect.DeepEqual(mp, decodeJSON(t, `{"a":{"b":[1]}}`)) {
+		t.Errorf("результат разделяет значения с патчем: %v", mp)
+	}
+}
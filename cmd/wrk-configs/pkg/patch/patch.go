@@ -0,0 +1,98 @@
+package patch
+
+// patch.go
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/keypath"
+)
+
+// Операции JSON Patch (RFC 6902)
+const (
+	OpAdd     = "add"
+	OpRemove  = "remove"
+	OpReplace = "replace"
+	OpMove    = "move"
+	OpCopy    = "copy"
+	OpTest    = "test"
+)
+
+// Operation одна операция JSON Patch. Path и From - JSON Pointer (RFC 6901)
+type Operation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	From  string      `json:"from,omitempty"`
+	Value interface{} `json:"value"`
+}
+
+// Patch документ JSON Patch - массив операций
+type Patch []Operation
+
+// MarshalJSON записывает value для add, replace и test даже если оно null
+func (op Operation) MarshalJSON() ([]byte, error) {
+	m := map[string]interface{}{"op": op.Op, "path": op.Path}
+	if op.From != "" {
+		m["from"] = op.From
+	}
+	switch op.Op {
+	case OpAdd, OpReplace, OpTest:
+		m["value"] = op.Value
+	}
+	return json.Marshal(m)
+}
+
+// Pointer переводит путь keypath ("server.middlewares[1]") в JSON Pointer
+// ("/server/middlewares/1"). Пути с шаблонами и отрицательными индексами
+// не имеют представления в JSON Pointer
+func Pointer(path string) (string, error) {
+	p, err := keypath.Parse(path)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	for _, seg := range p {
+		switch seg.Kind {
+		case keypath.KindKey:
+			b.WriteString(AppendPointer("", seg.Key))
+		case keypath.KindIndex:
+			if seg.Index < 0 {
+				return "", fmt.Errorf("путь %q: отрицательный индекс не поддерживается JSON Pointer", path)
+			}
+			b.WriteString("/" + strconv.Itoa(seg.Index))
+		default:
+			return "", fmt.Errorf("путь %q: шаблоны не поддерживаются JSON Pointer", path)
+		}
+	}
+	return b.String(), nil
+}
+
+// ParsePointer разбирает JSON Pointer на токены; "" - весь документ
+func ParsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if pointer[0] != '/' {
+		return nil, fmt.Errorf("JSON Pointer %q должен начинаться с /", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+	}
+	return tokens, nil
+}
+
+// AppendPointer добавляет к JSON Pointer токен (ключ или индекс), экранируя его
+func AppendPointer(pointer, token string) string {
+	return pointer + "/" + escapeToken(token)
+}
+
+// escapeToken экранирует ~ и / в токене JSON Pointer
+func escapeToken(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
@@ -0,0 +1,94 @@
+package patch
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestPointer(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"", ""},
+		{"server.port", "/server/port"},
+		{"server.middlewares[1]", "/server/middlewares/1"},
+		{"servers[0].tls.cert", "/servers/0/tls/cert"},
+	}
+	for _, tt := range tests {
+		got, err := Pointer(tt.path)
+		if err != nil {
+			t.Errorf("Pointer(%q): %v", tt.path, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Pointer(%q) = %q, ожидалось %q", tt.path, got, tt.want)
+		}
+	}
+
+	for _, path := range []string{"items[-1]", "servers.*.port"} {
+		if _, err := Pointer(path); err == nil {
+			t.Errorf("Pointer(%q): ожидалась ошибка", path)
+		}
+	}
+}
+
+func TestParsePointer(t *testing.T) {
+	tests := []struct {
+		pointer string
+		want    []string
+	}{
+		{"", nil},
+		{"/", []string{""}},
+		{"/foo/0", []string{"foo", "0"}},
+		{"/a~1b/m~0n", []string{"a/b", "m~n"}},
+		{"/~01", []string{"~1"}},
+	}
+	for _, tt := range tests {
+		got, err := ParsePointer(tt.pointer)
+		if err != nil {
+			t.Errorf("ParsePointer(%q): %v", tt.pointer, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParsePointer(%q) = %q, ожидалось %q", tt.pointer, got, tt.want)
+		}
+	}
+
+	if _, err := ParsePointer("foo"); err == nil {
+		t.Error("ParsePointer(\"foo\"): ожидалась ошибка")
+	}
+}
+
+func TestEscapeTokenRoundTrip(t *testing.T) {
+	for _, token := range []string{"a/b", "m~n", "~1", "~/~0"} {
+		tokens, err := ParsePointer("/" + escapeToken(token))
+		if err != nil || len(tokens) != 1 || tokens[0] != token {
+			t.Errorf("%q: получено %q, %v", token, tokens, err)
+		}
+	}
+}
+
+func TestOperationMarshalJSON(t *testing.T) {
+	tests := []struct {
+		op   Operation
+		want string
+	}{
+		{Operation{Op: OpAdd, Path: "/a"}, `{"op":"add","path":"/a","value":null}`},
+		{Operation{Op: OpRemove, Path: "/a"}, `{"op":"remove","path":"/a"}`},
+		{Operation{Op: OpMove, From: "/a", Path: "/b"}, `{"op":"move","path":"/b","from":"/a"}`},
+	}
+	for _, tt := range tests {
+		data, err := json.Marshal(tt.op)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got, want interface{}
+		_ = json.Unmarshal(data, &got)
+		_ = json.Unmarshal([]byte(tt.want), &want)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: получено %s, ожидалось %s", tt.op.Op, data, tt.want)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package profile
+
+// profile.go
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/merge"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/parsers"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/utils"
+)
+
+const (
+	// EnvVar переменная окружения с именем профиля
+	EnvVar = "APP_ENV"
+	// DefaultKey ключ блока профилей внутри файла:
+	//
+	//	server:
+	//	  port: 8080
+	//	profiles:
+	//	  production:
+	//	    server:
+	//	      port: 80
+	DefaultKey = "profiles"
+	// DefaultEnvironmentKey ключ конфигурации с профилем по умолчанию
+	// ("environment": "development" в test_config.json)
+	DefaultEnvironmentKey = "environment"
+)
+
+// Loader загружает базовую конфигурацию и накладывает на нее профиль.
+//
+// Профиль выбирается так: Profile, затем переменная окружения EnvVar,
+// затем строковое значение EnvironmentKey из самого файла.
+// На базовую конфигурацию по порядку накладываются блок Key.<профиль>
+// из того же файла и соседние файлы <имя>.<профиль>.<расширение> любого
+// формата (app.yml -> app.production.yml, app.production.json).
+// Блок Key удаляется из результата, а EnvironmentKey, если он есть в файле,
+// получает имя выбранного профиля
+type Loader struct {
+	// Profile явно выбранный профиль, например из флага командной строки
+	Profile string
+	// EnvVar переменная окружения с профилем, по умолчанию APP_ENV
+	EnvVar string
+	// Key ключ блока профилей, по умолчанию "profiles"
+	Key string
+	// EnvironmentKey ключ с профилем по умолчанию, по умолчанию "environment"
+	EnvironmentKey string
+	// Arrays правило слияния массивов профиля
+	Arrays merge.ArrayRule
+	// Load читает один файл; по умолчанию parsers.LoadDynamicFile.
+	// Позволяет, например, раскрывать $include в базовом файле и в профилях
+	Load func(path string) (map[string]interface{}, error)
+}
+
+// Result эффективная конфигурация профиля.
+// Source и Provenance показывают, из какого слоя взято каждое значение
+type Result struct {
+	*merge.Result
+	// Profile выбранный профиль; пустой, если профиль не выбран
+	Profile string
+	// Overlays имена наложенных слоев по порядку: блок в файле и файлы профиля
+	Overlays []string
+	// Files базовый файл и файлы профиля
+	Files []string
+}
+
+// NewLoader создает загрузчик с настройками по умолчанию
+func NewLoader() *Loader {
+	return &Loader{EnvVar: EnvVar, Key: DefaultKey, EnvironmentKey: DefaultEnvironmentKey}
+}
+
+// LoadFile загружает файл с профилем profile (пустой - из APP_ENV или файла)
+func LoadFile(path, profile string) (*Result, error) {
+	l := NewLoader()
+	l.Profile = profile
+	return l.LoadFile(path)
+}
+
+// Active возвращает явно заданный профиль или значение переменной окружения
+func (l *Loader) Active() string {
+	if l.Profile != "" {
+		return l.Profile
+	}
+	if l.EnvVar != "" {
+		return os.Getenv(l.EnvVar)
+	}
+	return os.Getenv(EnvVar)
+}
+
+// LoadFile загружает файл и накладывает на него выбранный профиль
+func (l *Loader) LoadFile(path string) (*Result, error) {
+	base, err := l.load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	key := l.key()
+	block, hasBlock := base[key]
+	delete(base, key)
+
+	profile := l.Active()
+	if profile == "" {
+		profile, _ = base[l.environmentKey()].(string)
+	}
+
+	result := &Result{Profile: profile, Files: []string{path}}
+
+	merger := merge.NewMerger()
+	merger.Arrays = l.Arrays
+	merger.Add(merge.MapSource(path, base))
+
+	if profile != "" {
+		if err := checkName(profile); err != nil {
+			return nil, err
+		}
+
+		if hasBlock {
+			overlay, err := blockOverlay(block, key, profile)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+			if overlay != nil {
+				name := path + ":" + key + "." + profile
+				merger.Add(merge.MapSource(name, overlay))
+				result.Overlays = append(result.Overlays, name)
+			}
+		}
+
+		files, err := Files(path, profile)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			data, err := l.load(file)
+			if err != nil {
+				return nil, err
+			}
+			delete(data, key)
+			merger.Add(merge.MapSource(file, data))
+			result.Overlays = append(result.Overlays, file)
+			result.Files = append(result.Files, file)
+		}
+	}
+
+	// Ключ environment отражает выбранный профиль, а не значение из базового файла
+	if _, ok := base[l.environmentKey()].(string); ok && profile != "" {
+		merger.Add(merge.MapSource("profile:"+profile, map[string]interface{}{l.environmentKey(): profile}))
+	}
+
+	merged, err := merger.Merge()
+	if err != nil {
+		return nil, err
+	}
+
+	result.Result = merged
+	return result, nil
+}
+
+// Files возвращает существующие файлы профиля для базового файла:
+// <имя>.<профиль>.<расширение> в той же директории, по алфавиту
+func Files(path, profile string) ([]string, error) {
+	if err := checkName(profile); err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	pattern := filepath.Join(filepath.Dir(path), name+"."+profile+".*")
+
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, match := range matches {
+		if utils.GetFormatByExtension(filepath.Ext(match)) != "" {
+			files = append(files, match)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// blockOverlay возвращает объект профиля из блока profiles или nil, если его нет
+func blockOverlay(block interface{}, key, profile string) (map[string]interface{}, error) {
+	profiles, ok := block.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s: ожидается объект с профилями, получено %T", key, block)
+	}
+
+	value, exists := profiles[profile]
+	if !exists {
+		return nil, nil
+	}
+	overlay, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s.%s: ожидается объект, получено %T", key, profile, value)
+	}
+	return overlay, nil
+}
+
+// checkName не допускает в имени профиля разделители путей и символы шаблонов
+func checkName(profile string) error {
+	if strings.ContainsAny(profile, `/\*?[`) || profile == "." || profile == ".." {
+		return fmt.Errorf("недопустимое имя профиля %q", profile)
+	}
+	return nil
+}
+
+func (l *Loader) load(path string) (map[string]interface{}, error) {
+	if l.Load != nil {
+		return l.Load(path)
+	}
+	data, _, err := parsers.LoadDynamicFile(path)
+	return data, err
+}
+
+func (l *Loader) key() string {
+	if l.Key == "" {
+		return DefaultKey
+	}
+	return l.Key
+}
+
+func (l *Loader) environmentKey() string {
+	if l.EnvironmentKey == "" {
+		return DefaultEnvironmentKey
+	}
+	return l.EnvironmentKey
+}
@@ -0,0 +1,80 @@
+package reader
+
+// edit.go
+
+import (
+	"fmt"
+
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/keypath"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/manager"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/types"
+)
+
+// Set записывает значение по пути, создавая недостающие объекты и массивы:
+// Set("server.tls.cert", "a.pem") создаст объект tls,
+// Set("server.middlewares[3]", "gzip") расширит массив до нужной длины
+func (cr *ConfigReader) Set(key string, value interface{}) error {
+	path, err := keypath.Parse(key)
+	if err != nil {
+		return err
+	}
+	if cr.Data == nil {
+		cr.Data = make(map[string]interface{})
+	}
+	return path.Set(cr.Data, value)
+}
+
+// Delete удаляет значение по пути
+func (cr *ConfigReader) Delete(key string) error {
+	path, err := keypath.Parse(key)
+	if err != nil {
+		return err
+	}
+	return path.Delete(cr.Data)
+}
+
+// Append добавляет значение в конец массива. Если ключа нет,
+// создается массив из одного элемента
+func (cr *ConfigReader) Append(key string, value interface{}) error {
+	existing, exists := cr.Get(key)
+	if !exists {
+		return cr.Set(key, []interface{}{value})
+	}
+
+	arr, ok := existing.([]interface{})
+	if !ok {
+		return fmt.Errorf("значение по ключу '%s' не является массивом", key)
+	}
+	return cr.Set(key, append(arr, keypath.Normalize(value)))
+}
+
+// Save сохраняет данные в исходный файл в его формате
+func (cr *ConfigReader) Save() error {
+	if cr.FilePath == "" {
+		return fmt.Errorf("файл конфигурации не задан: используйте SaveAs")
+	}
+	return cr.SaveAs(cr.FilePath, cr.Format)
+}
+
+// SaveAs сохраняет данные в файл в указанном формате.
+// Если формат пуст, он определяется как в manager.ConfigManager.Save.
+// После успешной записи ридер связывается с новым файлом
+func (cr *ConfigReader) SaveAs(filePath string, format types.ConfigFormat) error {
+	cm := manager.NewConfigManager()
+
+	if format == "" {
+		detected, err := cm.FormatOf(filePath)
+		if err != nil {
+			return err
+		}
+		format = detected
+	}
+
+	if err := cm.SaveAs(filePath, cr.Data, format); err != nil {
+		return err
+	}
+
+	cr.FilePath = filePath
+	cr.Format = format
+	return nil
+}
@@ -0,0 +1,189 @@
+package reader
+
+// getters.go
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/keypath"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/utils"
+)
+
+// ConversionError значение по ключу найдено, но не приводится к нужному типу
+type ConversionError struct {
+	Key   string
+	Value interface{}
+	Type  string
+	Err   error
+}
+
+func (e *ConversionError) Error() string {
+	return fmt.Sprintf("ключ '%s': не удалось преобразовать %#v в %s: %v", e.Key, e.Value, e.Type, e.Err)
+}
+
+func (e *ConversionError) Unwrap() error {
+	return e.Err
+}
+
+// GetDuration получает длительность: "1m30s", "250ms" или число секунд
+// ("request_timeout": 60 -> 60s)
+func (cr *ConfigReader) GetDuration(key string) (time.Duration, error) {
+	value, err := cr.lookup(key)
+	if err != nil {
+		return 0, err
+	}
+
+	d, err := utils.ToDuration(value)
+	if err != nil {
+		return 0, &ConversionError{Key: key, Value: value, Type: "time.Duration", Err: err}
+	}
+	return d, nil
+}
+
+// GetByteSize получает размер в байтах: число или строка "10MB", "512KiB", "1.5 GiB"
+// (KB, MB, GB - по 1000, KiB, MiB, GiB - по 1024)
+func (cr *ConfigReader) GetByteSize(key string) (int64, error) {
+	value, err := cr.lookup(key)
+	if err != nil {
+		return 0, err
+	}
+
+	size, err := utils.ToByteSize(value)
+	if err != nil {
+		return 0, &ConversionError{Key: key, Value: value, Type: "размер", Err: err}
+	}
+	return size, nil
+}
+
+// GetTime получает время: даты TOML или строки RFC 3339, "2006-01-02 15:04:05", "2006-01-02"
+func (cr *ConfigReader) GetTime(key string) (time.Time, error) {
+	value, err := cr.lookup(key)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	t, err := utils.ToTime(value)
+	if err != nil {
+		return time.Time{}, &ConversionError{Key: key, Value: value, Type: "time.Time", Err: err}
+	}
+	return t, nil
+}
+
+// GetURL получает адрес со схемой ("postgres://db:5432/app")
+func (cr *ConfigReader) GetURL(key string) (*url.URL, error) {
+	value, err := cr.lookup(key)
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := utils.ToURL(value)
+	if err != nil {
+		return nil, &ConversionError{Key: key, Value: value, Type: "URL", Err: err}
+	}
+	return u, nil
+}
+
+// GetIntSlice получает массив целых чисел. Строка "80, 443" разбивается по запятым
+func (cr *ConfigReader) GetIntSlice(key string) ([]int64, error) {
+	value, err := cr.lookup(key)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []interface{}
+	switch v := value.(type) {
+	case []interface{}:
+		items = v
+	case string:
+		for _, part := range utils.SplitList(v) {
+			items = append(items, part)
+		}
+	default:
+		return nil, &ConversionError{Key: key, Value: value, Type: "[]int64", Err: fmt.Errorf("значение не является массивом")}
+	}
+
+	result := make([]int64, len(items))
+	for i, item := range items {
+		n, err := utils.ToInt64(item)
+		if err != nil {
+			return nil, &ConversionError{Key: keypath.Index(key, i), Value: item, Type: "int64", Err: err}
+		}
+		result[i] = n
+	}
+	return result, nil
+}
+
+// Варианты GetXOr возвращают def, если ключ не найден
+// или значение не приводится к нужному типу
+
+func (cr *ConfigReader) GetStringOr(key, def string) string {
+	if s, err := cr.GetString(key); err == nil {
+		return s
+	}
+	return def
+}
+
+func (cr *ConfigReader) GetIntOr(key string, def int64) int64 {
+	if n, err := cr.GetInt(key); err == nil {
+		return n
+	}
+	return def
+}
+
+func (cr *ConfigReader) GetBoolOr(key string, def bool) bool {
+	if b, err := cr.GetBool(key); err == nil {
+		return b
+	}
+	return def
+}
+
+func (cr *ConfigReader) GetFloatOr(key string, def float64) float64 {
+	if f, err := cr.GetFloat(key); err == nil {
+		return f
+	}
+	return def
+}
+
+func (cr *ConfigReader) GetStringArrayOr(key string, def []string) []string {
+	if arr, err := cr.GetStringArray(key); err == nil {
+		return arr
+	}
+	return def
+}
+
+func (cr *ConfigReader) GetDurationOr(key string, def time.Duration) time.Duration {
+	if d, err := cr.GetDuration(key); err == nil {
+		return d
+	}
+	return def
+}
+
+func (cr *ConfigReader) GetByteSizeOr(key string, def int64) int64 {
+	if size, err := cr.GetByteSize(key); err == nil {
+		return size
+	}
+	return def
+}
+
+func (cr *ConfigReader) GetTimeOr(key string, def time.Time) time.Time {
+	if t, err := cr.GetTime(key); err == nil {
+		return t
+	}
+	return def
+}
+
+func (cr *ConfigReader) GetURLOr(key string, def *url.URL) *url.URL {
+	if u, err := cr.GetURL(key); err == nil {
+		return u
+	}
+	return def
+}
+
+func (cr *ConfigReader) GetIntSliceOr(key string, def []int64) []int64 {
+	if arr, err := cr.GetIntSlice(key); err == nil {
+		return arr
+	}
+	return def
+}
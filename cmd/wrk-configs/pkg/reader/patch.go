@@ -0,0 +1,42 @@
+package reader
+
+// patch.go
+
+import (
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/diff"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/patch"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/types"
+)
+
+// ApplyPatch применяет JSON Patch (RFC 6902) и возвращает внесенные изменения.
+// Если любая операция, включая test, не выполнена, данные не изменяются.
+// Файл изменяется только при Save, поэтому без Save это пробный прогон
+func (cr *ConfigReader) ApplyPatch(p patch.Patch) ([]diff.Change, error) {
+	applier := patch.NewApplier()
+	applier.Loose = cr.Format == types.FormatINI
+
+	result, err := applier.Apply(p, cr.Data)
+	if err != nil {
+		return nil, err
+	}
+	return cr.replaceData(result), nil
+}
+
+// ApplyMergePatch применяет JSON Merge Patch (RFC 7386) и возвращает внесенные изменения
+func (cr *ConfigReader) ApplyMergePatch(mp map[string]interface{}) []diff.Change {
+	return cr.replaceData(patch.MergePatch(cr.Data, mp))
+}
+
+// replaceData заменяет данные и возвращает различия со старыми
+func (cr *ConfigReader) replaceData(result map[string]interface{}) []diff.Change {
+	changes := cr.differ().Compare(cr.Data, result)
+	cr.Data = result
+	return changes
+}
+
+// differ сравнивает нестрого для INI, где все значения - строки
+func (cr *ConfigReader) differ() *diff.Differ {
+	d := diff.NewDiffer()
+	d.Loose = cr.Format == types.FormatINI
+	return d
+}
@@ -0,0 +1,299 @@
+package reader
+
+// reader.go
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/interpolate"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/keypath"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/parsers"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/secrets"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/types"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/utils"
+)
+
+// ConfigReader универсальный читатель конфигураций.
+// Ключи задаются на языке путей пакета keypath:
+// "database.host", "server.middlewares[-1]", "logging.outputs.*", "..password"
+type ConfigReader struct {
+	Data     map[string]interface{}
+	FilePath string
+	Format   types.ConfigFormat
+}
+
+// NewConfigReader создает новый ConfigReader
+func NewConfigReader() *ConfigReader {
+	return &ConfigReader{
+		Data: make(map[string]interface{}),
+	}
+}
+
+// ReadJSON читает JSON файл
+func (cr *ConfigReader) ReadJSON(filePath string) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return fmt.Errorf("не удалось открыть файл %s: %w", filePath, err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return fmt.Errorf("не удалось прочитать файл %s: %w", filePath, err)
+	}
+
+	if err := json.Unmarshal(data, &cr.Data); err != nil {
+		return fmt.Errorf("не удалось распарсить JSON из %s: %w", filePath, err)
+	}
+
+	cr.FilePath = filePath
+	cr.Format = types.FormatJSON
+	return nil
+}
+
+// ReadFile читает файл любого зарегистрированного формата
+func (cr *ConfigReader) ReadFile(filePath string) error {
+	data, format, err := parsers.LoadDynamicFile(filePath)
+	if err != nil {
+		return err
+	}
+
+	cr.Data = data
+	cr.FilePath = filePath
+	cr.Format = format
+	return nil
+}
+
+// lookup находит значение по пути. Для путей с шаблонами (* и ..)
+// возвращается []interface{} со всеми найденными значениями
+func (cr *ConfigReader) lookup(key string) (interface{}, error) {
+	path, err := keypath.Parse(key)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := path.Find(cr.Data)
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("ключ '%s' не найден", key)
+	}
+
+	if !path.HasWildcard() {
+		return matches[0].Value, nil
+	}
+
+	values := make([]interface{}, len(matches))
+	for i, match := range matches {
+		values[i] = match.Value
+	}
+	return values, nil
+}
+
+// Get получает значение по пути (например, "database.host" или "server.middlewares[0]").
+// Для путей с шаблонами возвращает []interface{} со всеми совпадениями
+func (cr *ConfigReader) Get(key string) (interface{}, bool) {
+	value, err := cr.lookup(key)
+	return value, err == nil
+}
+
+// GetAll возвращает все совпадения пути вместе с их конкретными путями
+func (cr *ConfigReader) GetAll(key string) ([]keypath.Match, error) {
+	return keypath.Query(cr.Data, key)
+}
+
+// GetString получает строковое значение
+func (cr *ConfigReader) GetString(key string) (string, error) {
+	value, err := cr.lookup(key)
+	if err != nil {
+		return "", err
+	}
+
+	if str, ok := value.(string); ok {
+		return str, nil
+	}
+
+	return "", fmt.Errorf("значение по ключу '%s' не является строкой", key)
+}
+
+// GetInt получает целочисленное значение. Строки ("5432" из INI или окружения)
+// разбираются, дробные числа считаются ошибкой
+func (cr *ConfigReader) GetInt(key string) (int64, error) {
+	value, err := cr.lookup(key)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := utils.ToInt64(value)
+	if err != nil {
+		return 0, &ConversionError{Key: key, Value: value, Type: "int64", Err: err}
+	}
+	return n, nil
+}
+
+// GetBool получает булево значение. Строки true/false, yes/no, on/off, 1/0 разбираются
+func (cr *ConfigReader) GetBool(key string) (bool, error) {
+	value, err := cr.lookup(key)
+	if err != nil {
+		return false, err
+	}
+
+	b, err := utils.ToBool(value)
+	if err != nil {
+		return false, &ConversionError{Key: key, Value: value, Type: "bool", Err: err}
+	}
+	return b, nil
+}
+
+// GetFloat получает значение с плавающей точкой. Целые числа и строки приводятся
+func (cr *ConfigReader) GetFloat(key string) (float64, error) {
+	value, err := cr.lookup(key)
+	if err != nil {
+		return 0, err
+	}
+
+	f, err := utils.ToFloat64(value)
+	if err != nil {
+		return 0, &ConversionError{Key: key, Value: value, Type: "float64", Err: err}
+	}
+	return f, nil
+}
+
+// GetArray получает массив значений
+func (cr *ConfigReader) GetArray(key string) ([]interface{}, error) {
+	value, err := cr.lookup(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if arr, ok := value.([]interface{}); ok {
+		return arr, nil
+	}
+
+	return nil, fmt.Errorf("значение по ключу '%s' не является массивом", key)
+}
+
+// GetStringArray получает массив строк. Строка "a, b, c" (INI, окружение)
+// разбивается по запятым
+func (cr *ConfigReader) GetStringArray(key string) ([]string, error) {
+	value, err := cr.lookup(key)
+	if err != nil {
+		return nil, err
+	}
+	if s, ok := value.(string); ok {
+		return utils.SplitList(s), nil
+	}
+
+	arr, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("значение по ключу '%s' не является массивом", key)
+	}
+
+	result := make([]string, len(arr))
+	for i, item := range arr {
+		if str, ok := item.(string); ok {
+			result[i] = str
+		} else {
+			return nil, fmt.Errorf("элемент %d массива '%s' не является строкой", i, key)
+		}
+	}
+
+	return result, nil
+}
+
+// GetObject получает вложенный объект
+func (cr *ConfigReader) GetObject(key string) (map[string]interface{}, error) {
+	value, err := cr.lookup(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if obj, ok := value.(map[string]interface{}); ok {
+		return obj, nil
+	}
+
+	return nil, fmt.Errorf("значение по ключу '%s' не является объектом", key)
+}
+
+// Keys возвращает конкретные пути всех совпадений выражения
+func (cr *ConfigReader) Keys(key string) ([]string, error) {
+	matches, err := cr.GetAll(key)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, len(matches))
+	for i, match := range matches {
+		keys[i] = match.Path
+	}
+	return keys, nil
+}
+
+// GetAllKeys возвращает отсортированные пути всех значений, включая
+// вложенные ключи и элементы массивов ("server.middlewares[0]").
+// Ключи с точками экранируются, поэтому каждый путь можно передать в Get
+func (cr *ConfigReader) GetAllKeys() []string {
+	keys, _ := cr.Keys("..*")
+	sort.Strings(keys)
+	return keys
+}
+
+// PrintStructure выводит структуру конфигурации.
+// Значения ключей с паролями, секретами и токенами скрываются (secrets.Redact)
+func (cr *ConfigReader) PrintStructure() {
+	fmt.Println("Структура конфигурации:")
+	cr.printValue("", secrets.Redact(cr.Data), 0)
+}
+
+// printValue рекурсивно выводит значения
+func (cr *ConfigReader) printValue(key string, value interface{}, indent int) {
+	indentStr := strings.Repeat("  ", indent)
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if key != "" {
+			fmt.Printf("%s%s: {\n", indentStr, key)
+		}
+		for k, val := range v {
+			cr.printValue(k, val, indent+1)
+		}
+		if key != "" {
+			fmt.Printf("%s}\n", indentStr)
+		}
+	case []interface{}:
+		fmt.Printf("%s%s: [\n", indentStr, key)
+		for i, item := range v {
+			cr.printValue(fmt.Sprintf("[%d]", i), item, indent+1)
+		}
+		fmt.Printf("%s]\n", indentStr)
+	default:
+		fmt.Printf("%s%s: %v (%T)\n", indentStr, key, v, v)
+	}
+}
+
+// ToJSON конвертирует данные обратно в JSON
+func (cr *ConfigReader) ToJSON(pretty bool) ([]byte, error) {
+	if pretty {
+		return json.MarshalIndent(cr.Data, "", "  ")
+	}
+	return json.Marshal(cr.Data)
+}
+
+// Interpolate раскрывает подстановки ${key}, ${env:NAME} и ${file:path}
+// во всех строковых значениях. Если ip == nil, используется interpolate.New().
+// Раскрытые значения попадут и в файл при Save
+func (cr *ConfigReader) Interpolate(ip *interpolate.Interpolator) error {
+	if ip == nil {
+		ip = interpolate.New()
+	}
+	return ip.Apply(cr.Data)
+}
+
+// Decrypt расшифровывает значения ENC[...] ключом c.
+// Расшифрованные значения попадут и в файл при Save
+func (cr *ConfigReader) Decrypt(c *secrets.Cipher) error {
+	return c.Apply(cr.Data)
+}
@@ -0,0 +1,42 @@
+package reader
+
+// unmarshal.go
+
+import (
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/decode"
+)
+
+// Unmarshal декодирует значение по пути (пустой путь - вся конфигурация)
+// в v с приведением типов ("5432" -> int). Неизвестные ключи игнорируются:
+//
+//	var db DatabaseConfig
+//	err := cr.Unmarshal("database", &db)
+func (cr *ConfigReader) Unmarshal(key string, v interface{}) error {
+	_, err := cr.UnmarshalWith(key, v, decode.NewDecoder())
+	return err
+}
+
+// UnmarshalStrict как Unmarshal, но ключи без соответствующих полей считаются ошибкой
+func (cr *ConfigReader) UnmarshalStrict(key string, v interface{}) error {
+	d := decode.NewDecoder()
+	d.Strict = true
+	_, err := cr.UnmarshalWith(key, v, d)
+	return err
+}
+
+// UnmarshalWith декодирует значение по пути заданным декодером и возвращает
+// отчет с неиспользованными ключами. Пути в ошибках и отчете полные ("database.port")
+func (cr *ConfigReader) UnmarshalWith(key string, v interface{}, d *decode.Decoder) (*decode.Report, error) {
+	var value interface{} = cr.Data
+	if key != "" {
+		found, err := cr.lookup(key)
+		if err != nil {
+			return nil, err
+		}
+		value = found
+	}
+
+	withPrefix := *d
+	withPrefix.Prefix = key
+	return withPrefix.Decode(value, v)
+}
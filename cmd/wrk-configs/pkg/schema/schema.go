@@ -0,0 +1,156 @@
+package schema
+
+// schema.go
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/keypath"
+)
+
+// Schema подмножество JSON Schema, достаточное для проверки конфигураций:
+// type, required, enum, minimum/maximum (и exclusive-варианты),
+// minLength/maxLength, pattern, properties, additionalProperties,
+// items, minItems/maxItems
+type Schema struct {
+	Schema      string `json:"$schema,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+
+	Type TypeList      `json:"type,omitempty"`
+	Enum []interface{} `json:"enum,omitempty"`
+
+	Minimum          *float64 `json:"minimum,omitempty"`
+	Maximum          *float64 `json:"maximum,omitempty"`
+	ExclusiveMinimum *float64 `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum *float64 `json:"exclusiveMaximum,omitempty"`
+
+	MinLength *int   `json:"minLength,omitempty"`
+	MaxLength *int   `json:"maxLength,omitempty"`
+	Pattern   string `json:"pattern,omitempty"`
+
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Additional        `json:"additionalProperties,omitempty"`
+
+	Items    *Schema `json:"items,omitempty"`
+	MinItems *int    `json:"minItems,omitempty"`
+	MaxItems *int    `json:"maxItems,omitempty"`
+
+	pattern *regexp.Regexp
+}
+
+// TypeList значение ключевого слова type: одна строка или массив строк
+type TypeList []string
+
+func (t *TypeList) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = TypeList{single}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("type должен быть строкой или массивом строк: %w", err)
+	}
+	*t = list
+	return nil
+}
+
+func (t TypeList) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+// Additional значение additionalProperties: булево значение или схема
+type Additional struct {
+	Allowed bool
+	Schema  *Schema
+}
+
+func (a *Additional) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("true")) || bytes.Equal(bytes.TrimSpace(data), []byte("false")) {
+		return json.Unmarshal(data, &a.Allowed)
+	}
+
+	a.Allowed = true
+	a.Schema = new(Schema)
+	return json.Unmarshal(data, a.Schema)
+}
+
+func (a Additional) MarshalJSON() ([]byte, error) {
+	if a.Schema != nil {
+		return json.Marshal(a.Schema)
+	}
+	return json.Marshal(a.Allowed)
+}
+
+// Parse разбирает схему в формате JSON и компилирует регулярные выражения
+func Parse(data []byte) (*Schema, error) {
+	var s Schema
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("не удалось распарсить схему: %w", err)
+	}
+	if err := s.compile(""); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Load читает схему из файла
+func Load(path string) (*Schema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать схему %s: %w", path, err)
+	}
+
+	s, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+// compile рекурсивно проверяет схему и компилирует pattern
+func (s *Schema) compile(path string) error {
+	for _, t := range s.Type {
+		switch t {
+		case "object", "array", "string", "number", "integer", "boolean", "null":
+		default:
+			return fmt.Errorf("схема %s: неизвестный тип %q", displayPath(path), t)
+		}
+	}
+
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("схема %s: неверный pattern: %w", displayPath(path), err)
+		}
+		s.pattern = re
+	}
+
+	for key, prop := range s.Properties {
+		if err := prop.compile(keypath.Join(path, key)); err != nil {
+			return err
+		}
+	}
+	if s.AdditionalProperties != nil && s.AdditionalProperties.Schema != nil {
+		if err := s.AdditionalProperties.Schema.compile(path + "[*]"); err != nil {
+			return err
+		}
+	}
+	if s.Items != nil {
+		if err := s.Items.compile(path + "[]"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
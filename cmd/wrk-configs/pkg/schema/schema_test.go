@@ -0,0 +1,167 @@
+package schema
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testSchema = `{
+	"type": "object",
+	"required": ["server", "name"],
+	"additionalProperties": false,
+	"properties": {
+		"name": {"type": "string", "minLength": 2, "maxLength": 5, "pattern": "^[a-z]+$"},
+		"mode": {"enum": ["dev", "prod", 1]},
+		"server": {
+			"type": "object",
+			"required": ["port"],
+			"properties": {
+				"port": {"type": "integer", "minimum": 1, "maximum": 65535},
+				"ratio": {"type": "number", "exclusiveMinimum": 0, "exclusiveMaximum": 1},
+				"debug": {"type": "boolean"},
+				"tags": {"type": "array", "items": {"type": "string"}, "minItems": 1, "maxItems": 2}
+			},
+			"additionalProperties": {"type": "string"}
+		},
+		"optional": {"type": ["string", "null"]}
+	}
+}`
+
+func TestValidate(t *testing.T) {
+	s, err := Parse([]byte(testSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		value map[string]interface{}
+		paths []string
+	}{
+		{
+			name: "корректная конфигурация",
+			value: map[string]interface{}{
+				"name": "app", "mode": "dev", "optional": nil,
+				"server": map[string]interface{}{"port": 8080, "ratio": 0.5, "debug": true, "tags": []interface{}{"a"}, "extra": "x"},
+			},
+		},
+		{
+			name:  "отсутствуют обязательные ключи",
+			value: map[string]interface{}{"server": map[string]interface{}{}},
+			paths: []string{"name", "server.port"},
+		},
+		{
+			name: "нарушения значений",
+			value: map[string]interface{}{
+				"name": "A", "mode": "test", "unknown": 1, "optional": 1,
+				"server": map[string]interface{}{
+					"port": 8080.5, "ratio": 1.0, "debug": "yes",
+					"tags": []interface{}{"a", 1, "c"}, "extra": 2,
+				},
+			},
+			paths: []string{
+				"mode", "name", "name", "optional",
+				"server.debug", "server.extra", "server.port", "server.ratio",
+				"server.tags", "server.tags[1]", "unknown",
+			},
+		},
+		{
+			name:  "числа разных типов",
+			value: map[string]interface{}{"name": "ab", "mode": int64(1), "server": map[string]interface{}{"port": int64(0)}},
+			paths: []string{"server.port"},
+		},
+	}
+	for _, tt := range tests {
+		var paths []string
+		for _, v := range s.Validate(tt.value, Options{}) {
+			paths = append(paths, v.Path)
+		}
+		if !reflect.DeepEqual(paths, tt.paths) {
+			t.Errorf("%s: пути = %q, ожидалось %q", tt.name, paths, tt.paths)
+		}
+	}
+}
+
+func TestValidateCoerceStrings(t *testing.T) {
+	s, err := Parse([]byte(testSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	value := map[string]interface{}{
+		"name": "app", "mode": "1",
+		"server": map[string]interface{}{"port": "8080", "debug": "true", "tags": "a, b"},
+	}
+
+	if violations := s.Validate(value, Options{}); len(violations) == 0 {
+		t.Error("без CoerceStrings строки не должны проходить проверку типов")
+	}
+	if violations := s.Validate(value, Options{CoerceStrings: true}); len(violations) != 0 {
+		t.Errorf("CoerceStrings: %v", violations)
+	}
+
+	value["server"].(map[string]interface{})["port"] = "99999"
+	violations := s.Validate(value, Options{CoerceStrings: true})
+	if len(violations) != 1 || violations[0].Path != "server.port" {
+		t.Errorf("CoerceStrings с нарушением: %v", violations)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, data := range []string{
+		`{"type": "float"}`,
+		`{"properties": {"a": {"pattern": "("}}}`,
+		`{"type": 1}`,
+		`{`,
+	} {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("Parse(%s): ожидалась ошибка", data)
+		}
+	}
+}
+
+func TestValidateFilePositions(t *testing.T) {
+	s, err := Parse([]byte(testSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+
+	yaml := filepath.Join(dir, "app.yaml")
+	if err := os.WriteFile(yaml, []byte("name: app\nserver:\n  port: 0\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	violations, err := s.ValidateFile(yaml)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(violations) != 1 || violations[0].Position.Line != 3 {
+		t.Errorf("YAML: %v", violations)
+	}
+	if got := violations[0].String(); !strings.Contains(got, "server.port") {
+		t.Errorf("String = %q", got)
+	}
+
+	// Для INI строки приводятся к типам схемы
+	ini := filepath.Join(dir, "app.ini")
+	if err := os.WriteFile(ini, []byte("name = app\n[server]\nport = 8080\ndebug = true\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if violations, err := s.ValidateFile(ini); err != nil || len(violations) != 0 {
+		t.Errorf("INI: %v, %v", violations, err)
+	}
+}
+
+func TestExampleSchemas(t *testing.T) {
+	paths, err := filepath.Glob("../../configs/schemas/*.schema.json")
+	if err != nil || len(paths) == 0 {
+		t.Fatalf("схемы не найдены: %v", err)
+	}
+	for _, path := range paths {
+		if _, err := Load(path); err != nil {
+			t.Errorf("%s: %v", path, err)
+		}
+	}
+}
@@ -0,0 +1,354 @@
+package schema
+
+// validate.go
+
+import (
+	"encoding"
+	"fmt"
+	"math"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/keypath"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/parsers"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/types"
+)
+
+// Violation нарушение схемы по пути ключа
+type Violation struct {
+	Path     string
+	Message  string
+	Position types.Position
+}
+
+func (v Violation) String() string {
+	if v.Position.IsValid() {
+		return fmt.Sprintf("%s: %s: %s", v.Position, displayPath(v.Path), v.Message)
+	}
+	return fmt.Sprintf("%s: %s", displayPath(v.Path), v.Message)
+}
+
+// Options настройки проверки
+type Options struct {
+	// CoerceStrings разрешает строковые значения там, где схема ожидает
+	// число, булево значение или массив (значения INI всегда строки)
+	CoerceStrings bool
+}
+
+// Validate проверяет динамическое значение и возвращает все нарушения,
+// отсортированные по пути
+func (s *Schema) Validate(value interface{}, opts Options) []Violation {
+	v := &validator{opts: opts}
+	v.validate(s, value, "")
+
+	sort.SliceStable(v.violations, func(i, j int) bool {
+		return v.violations[i].Path < v.violations[j].Path
+	})
+	return v.violations
+}
+
+// ValidateFile загружает файл любого поддерживаемого формата, проверяет его
+// и дополняет нарушения позициями ключей в исходном файле.
+// Для INI автоматически включается CoerceStrings
+func (s *Schema) ValidateFile(path string) ([]Violation, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать файл %s: %w", path, err)
+	}
+
+	format, err := parsers.DetectFormat(path, data)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := parsers.ParseDynamic(format, data)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось распарсить %s из %s: %w", format, path, err)
+	}
+
+	violations := s.Validate(value, Options{CoerceStrings: format == types.FormatINI})
+
+	positions, err := parsers.KeyPositions(format, data)
+	if err == nil {
+		for i := range violations {
+			violations[i].Position = parsers.LookupPosition(positions, violations[i].Path)
+		}
+	}
+
+	return violations, nil
+}
+
+// validator накапливает нарушения при обходе
+type validator struct {
+	opts       Options
+	violations []Violation
+}
+
+func (v *validator) report(path, format string, args ...interface{}) {
+	v.violations = append(v.violations, Violation{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) validate(s *Schema, value interface{}, path string) {
+	if s == nil {
+		return
+	}
+
+	if len(s.Type) > 0 {
+		coerced, ok := v.matchType(s.Type, value)
+		if !ok {
+			v.report(path, "ожидался тип %s, получено %s", strings.Join(s.Type, " или "), typeName(value))
+			return
+		}
+		value = coerced
+	}
+
+	if len(s.Enum) > 0 && !v.inEnum(s.Enum, value) {
+		v.report(path, "значение %s не входит в допустимые %s", formatValue(value), formatEnum(s.Enum))
+	}
+
+	switch val := value.(type) {
+	case map[string]interface{}:
+		v.validateObject(s, val, path)
+	case []interface{}:
+		v.validateArray(s, val, path)
+	case string:
+		v.validateString(s, val, path)
+	default:
+		if n, ok := toNumber(value); ok {
+			v.validateNumber(s, n, path)
+		}
+	}
+}
+
+func (v *validator) validateObject(s *Schema, obj map[string]interface{}, path string) {
+	for _, key := range s.Required {
+		if _, exists := obj[key]; !exists {
+			v.report(keypath.Join(path, key), "обязательный ключ отсутствует")
+		}
+	}
+
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		keyPath := keypath.Join(path, key)
+
+		if prop, exists := s.Properties[key]; exists {
+			v.validate(prop, obj[key], keyPath)
+			continue
+		}
+
+		if s.AdditionalProperties == nil {
+			continue
+		}
+		if !s.AdditionalProperties.Allowed {
+			v.report(keyPath, "ключ не разрешен схемой")
+			continue
+		}
+		v.validate(s.AdditionalProperties.Schema, obj[key], keyPath)
+	}
+}
+
+func (v *validator) validateArray(s *Schema, arr []interface{}, path string) {
+	if s.MinItems != nil && len(arr) < *s.MinItems {
+		v.report(path, "элементов %d, минимум %d", len(arr), *s.MinItems)
+	}
+	if s.MaxItems != nil && len(arr) > *s.MaxItems {
+		v.report(path, "элементов %d, максимум %d", len(arr), *s.MaxItems)
+	}
+
+	for i, item := range arr {
+		v.validate(s.Items, item, keypath.Index(path, i))
+	}
+}
+
+func (v *validator) validateString(s *Schema, str string, path string) {
+	length := utf8.RuneCountInString(str)
+	if s.MinLength != nil && length < *s.MinLength {
+		v.report(path, "длина %d, минимум %d", length, *s.MinLength)
+	}
+	if s.MaxLength != nil && length > *s.MaxLength {
+		v.report(path, "длина %d, максимум %d", length, *s.MaxLength)
+	}
+	if s.pattern != nil && !s.pattern.MatchString(str) {
+		v.report(path, "значение %q не соответствует шаблону %s", str, s.Pattern)
+	}
+}
+
+func (v *validator) validateNumber(s *Schema, n float64, path string) {
+	if s.Minimum != nil && n < *s.Minimum {
+		v.report(path, "значение %g меньше минимума %g", n, *s.Minimum)
+	}
+	if s.Maximum != nil && n > *s.Maximum {
+		v.report(path, "значение %g больше максимума %g", n, *s.Maximum)
+	}
+	if s.ExclusiveMinimum != nil && n <= *s.ExclusiveMinimum {
+		v.report(path, "значение %g должно быть больше %g", n, *s.ExclusiveMinimum)
+	}
+	if s.ExclusiveMaximum != nil && n >= *s.ExclusiveMaximum {
+		v.report(path, "значение %g должно быть меньше %g", n, *s.ExclusiveMaximum)
+	}
+}
+
+// matchType проверяет тип значения и при CoerceStrings
+// возвращает значение, приведенное к подходящему типу
+func (v *validator) matchType(allowed TypeList, value interface{}) (interface{}, bool) {
+	for _, t := range allowed {
+		if hasType(t, value) {
+			return value, true
+		}
+	}
+
+	str, ok := value.(string)
+	if !ok || !v.opts.CoerceStrings {
+		return value, false
+	}
+
+	for _, t := range allowed {
+		switch t {
+		case "integer":
+			if n, err := strconv.ParseInt(strings.TrimSpace(str), 10, 64); err == nil {
+				return float64(n), true
+			}
+		case "number":
+			if n, err := strconv.ParseFloat(strings.TrimSpace(str), 64); err == nil {
+				return n, true
+			}
+		case "boolean":
+			if b, err := strconv.ParseBool(strings.TrimSpace(str)); err == nil {
+				return b, true
+			}
+		case "array":
+			parts := strings.Split(str, ",")
+			arr := make([]interface{}, len(parts))
+			for i, part := range parts {
+				arr[i] = strings.TrimSpace(part)
+			}
+			return arr, true
+		}
+	}
+
+	return value, false
+}
+
+// inEnum сравнивает значение с вариантами enum; числа сравниваются по значению
+func (v *validator) inEnum(enum []interface{}, value interface{}) bool {
+	for _, candidate := range enum {
+		if equalValues(candidate, value) {
+			return true
+		}
+		if str, ok := value.(string); ok && v.opts.CoerceStrings && formatScalar(candidate) == str {
+			return true
+		}
+	}
+	return false
+}
+
+// hasType проверяет соответствие значения типу JSON Schema
+func hasType(t string, value interface{}) bool {
+	switch t {
+	case "null":
+		return value == nil
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		return isString(value)
+	case "number":
+		_, ok := toNumber(value)
+		return ok
+	case "integer":
+		n, ok := toNumber(value)
+		return ok && n == math.Trunc(n)
+	default:
+		return false
+	}
+}
+
+// isString считает строками также даты и значения с текстовым представлением
+func isString(value interface{}) bool {
+	switch value.(type) {
+	case string, time.Time, encoding.TextMarshaler:
+		return true
+	default:
+		return false
+	}
+}
+
+// toNumber приводит числовые типы разных парсеров к float64
+func toNumber(value interface{}) (float64, bool) {
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	default:
+		return 0, false
+	}
+}
+
+// equalValues сравнивает значения с учетом разных числовых типов
+func equalValues(a, b interface{}) bool {
+	if na, ok := toNumber(a); ok {
+		nb, ok := toNumber(b)
+		return ok && na == nb
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// typeName возвращает имя типа JSON Schema для значения
+func typeName(value interface{}) string {
+	for _, t := range []string{"null", "boolean", "object", "array", "string", "integer", "number"} {
+		if hasType(t, value) {
+			return t
+		}
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func formatScalar(value interface{}) string {
+	if n, ok := toNumber(value); ok {
+		return strconv.FormatFloat(n, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
+
+func formatValue(value interface{}) string {
+	if str, ok := value.(string); ok {
+		return strconv.Quote(str)
+	}
+	return formatScalar(value)
+}
+
+func formatEnum(enum []interface{}) string {
+	items := make([]string, len(enum))
+	for i, item := range enum {
+		items[i] = formatValue(item)
+	}
+	return "[" + strings.Join(items, ", ") + "]"
+}
+
+// displayPath возвращает путь для вывода пользователю
+func displayPath(path string) string {
+	if path == "" {
+		return "(корень)"
+	}
+	return path
+}
@@ -0,0 +1,165 @@
+package secrets
+
+// cipher.go
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/keypath"
+)
+
+// Зашифрованные значения записываются в конфигурации строкой
+//
+//	password: ENC[AES256_GCM,<base64(nonce + шифротекст)>]
+//
+// и расшифровываются при загрузке ключом из локального файла.
+// Файл ключа содержит 32 байта: как есть, в hex или в base64 (см. GenerateKey)
+
+const (
+	// KeyFileEnv переменная окружения с путем к файлу ключа
+	KeyFileEnv = "CONFIG_KEY_FILE"
+	// KeySize размер ключа AES-256
+	KeySize = 32
+
+	prefix = "ENC[AES256_GCM,"
+	suffix = "]"
+)
+
+// Cipher шифрует и расшифровывает значения ENC[...]
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+// NewCipher создает Cipher для 32-байтового ключа
+func NewCipher(key []byte) (*Cipher, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("ключ должен быть длиной %d байт, получено %d", KeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Cipher{aead: aead}, nil
+}
+
+// LoadKeyFile читает ключ из файла и создает Cipher
+func LoadKeyFile(path string) (*Cipher, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать ключ %s: %w", path, err)
+	}
+	key, err := parseKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return NewCipher(key)
+}
+
+// GenerateKey возвращает новый случайный ключ в hex для записи в файл ключа
+func GenerateKey() (string, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(key), nil
+}
+
+// IsEncrypted сообщает, является ли строка зашифрованным значением
+func IsEncrypted(s string) bool {
+	return strings.HasPrefix(s, prefix) && strings.HasSuffix(s, suffix)
+}
+
+// Encrypt шифрует значение и возвращает строку ENC[...]
+func (c *Cipher) Encrypt(plain string) (string, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := c.aead.Seal(nonce, nonce, []byte(plain), nil)
+	return prefix + base64.StdEncoding.EncodeToString(sealed) + suffix, nil
+}
+
+// Decrypt расшифровывает строку ENC[...]
+func (c *Cipher) Decrypt(value string) (string, error) {
+	if !IsEncrypted(value) {
+		return "", errors.New("значение не зашифровано (ожидается " + prefix + "...])")
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(value[len(prefix) : len(value)-len(suffix)])
+	if err != nil {
+		return "", fmt.Errorf("неверный base64: %w", err)
+	}
+	if len(sealed) < c.aead.NonceSize() {
+		return "", errors.New("слишком короткое зашифрованное значение")
+	}
+
+	nonce, data := sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():]
+	plain, err := c.aead.Open(nil, nonce, data, nil)
+	if err != nil {
+		return "", errors.New("не удалось расшифровать: неверный ключ или поврежденное значение")
+	}
+	return string(plain), nil
+}
+
+// Apply расшифровывает все значения ENC[...] в динамической конфигурации.
+// Возвращает все ошибки сразу, объединенные errors.Join
+func (c *Cipher) Apply(data map[string]interface{}) error {
+	var errs []error
+	for _, key := range sortedKeys(data) {
+		data[key] = c.apply(keypath.Join("", key), data[key], &errs)
+	}
+	return errors.Join(errs...)
+}
+
+// apply возвращает значение с расшифрованными строками
+func (c *Cipher) apply(path string, value interface{}, errs *[]error) interface{} {
+	switch v := value.(type) {
+	case string:
+		if !IsEncrypted(v) {
+			return v
+		}
+		plain, err := c.Decrypt(v)
+		if err != nil {
+			*errs = append(*errs, fmt.Errorf("%s: %w", path, err))
+			return v
+		}
+		return plain
+	case map[string]interface{}:
+		for _, key := range sortedKeys(v) {
+			v[key] = c.apply(keypath.Join(path, key), v[key], errs)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = c.apply(keypath.Index(path, i), item, errs)
+		}
+	}
+	return value
+}
+
+// parseKey принимает ключ в hex, base64 или как есть
+func parseKey(data []byte) ([]byte, error) {
+	if len(data) == KeySize {
+		return data, nil
+	}
+
+	text := strings.TrimSpace(string(data))
+	if key, err := hex.DecodeString(text); err == nil && len(key) == KeySize {
+		return key, nil
+	}
+	if key, err := base64.StdEncoding.DecodeString(text); err == nil && len(key) == KeySize {
+		return key, nil
+	}
+	return nil, fmt.Errorf("ожидается ключ из %d байт (как есть, hex или base64)", KeySize)
+}
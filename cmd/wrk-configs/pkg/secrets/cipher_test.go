@@ -0,0 +1,160 @@
+package secrets
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCipherRoundTrip(t *testing.T) {
+	c := newTestCipher(t, 1)
+
+	for _, plain := range []string{"", "hunter2", "пароль с пробелами", strings.Repeat("x", 1000)} {
+		enc, err := c.Encrypt(plain)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !IsEncrypted(enc) {
+			t.Errorf("Encrypt(%q) = %q", plain, enc)
+		}
+		got, err := c.Decrypt(enc)
+		if err != nil {
+			t.Errorf("Decrypt(Encrypt(%q)): %v", plain, err)
+			continue
+		}
+		if got != plain {
+			t.Errorf("Decrypt(Encrypt(%q)) = %q", plain, got)
+		}
+	}
+
+	// Случайный nonce: одинаковые значения шифруются по-разному
+	a, _ := c.Encrypt("same")
+	b, _ := c.Encrypt("same")
+	if a == b {
+		t.Error("два шифрования одного значения совпали")
+	}
+}
+
+func TestDecryptErrors(t *testing.T) {
+	c := newTestCipher(t, 1)
+	enc, err := c.Encrypt("hunter2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{"не зашифровано", "hunter2"},
+		{"неверный base64", prefix + "!!!" + suffix},
+		{"слишком короткое", prefix + base64.StdEncoding.EncodeToString([]byte("x")) + suffix},
+		{"поврежденное", enc[:len(enc)-3] + "A=" + suffix},
+	}
+	for _, tt := range tests {
+		if _, err := c.Decrypt(tt.value); err == nil {
+			t.Errorf("%s: ожидалась ошибка", tt.name)
+		}
+	}
+
+	if _, err := newTestCipher(t, 2).Decrypt(enc); err == nil {
+		t.Error("неверный ключ: ожидалась ошибка")
+	}
+}
+
+func TestNewCipherKeySize(t *testing.T) {
+	if _, err := NewCipher(make([]byte, 16)); err == nil {
+		t.Error("ключ 16 байт: ожидалась ошибка")
+	}
+}
+
+func TestLoadKeyFile(t *testing.T) {
+	key := testKey(1)
+	dir := t.TempDir()
+
+	tests := []struct {
+		name string
+		data []byte
+		ok   bool
+	}{
+		{"как есть", key, true},
+		{"hex", []byte(hex.EncodeToString(key) + "\n"), true},
+		{"base64", []byte(base64.StdEncoding.EncodeToString(key)), true},
+		{"короткий", []byte("abc"), false},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(dir, "key")
+		if err := os.WriteFile(path, tt.data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		_, err := LoadKeyFile(path)
+		if (err == nil) != tt.ok {
+			t.Errorf("%s: ошибка = %v", tt.name, err)
+		}
+	}
+
+	if _, err := LoadKeyFile(filepath.Join(dir, "missing")); err == nil {
+		t.Error("нет файла: ожидалась ошибка")
+	}
+
+	generated, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key, err := parseKey([]byte(generated)); err != nil || len(key) != KeySize {
+		t.Errorf("GenerateKey: %q не разбирается: %v", generated, err)
+	}
+}
+
+func TestApply(t *testing.T) {
+	c := newTestCipher(t, 1)
+	enc, _ := c.Encrypt("hunter2")
+	wrong, _ := newTestCipher(t, 2).Encrypt("x")
+
+	data := map[string]interface{}{
+		"db":    map[string]interface{}{"password": enc, "host": "localhost"},
+		"list":  []interface{}{enc, 1},
+		"plain": "ENC-like",
+	}
+	if err := c.Apply(data); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"db":    map[string]interface{}{"password": "hunter2", "host": "localhost"},
+		"list":  []interface{}{"hunter2", 1},
+		"plain": "ENC-like",
+	}
+	if !reflect.DeepEqual(data, want) {
+		t.Errorf("получено %v", data)
+	}
+
+	// Ошибки собираются по всем значениям с путями
+	data = map[string]interface{}{"a": wrong, "b": map[string]interface{}{"c": wrong}}
+	err := c.Apply(data)
+	if err == nil || !strings.Contains(err.Error(), "a:") || !strings.Contains(err.Error(), "b.c:") {
+		t.Errorf("ошибка = %v", err)
+	}
+}
+
+// testKey возвращает детерминированный ключ
+func testKey(seed byte) []byte {
+	key := make([]byte, KeySize)
+	for i := range key {
+		key[i] = seed + byte(i)
+	}
+	return key
+}
+
+// newTestCipher создает Cipher с ключом testKey(seed)
+func newTestCipher(t *testing.T, seed byte) *Cipher {
+	t.Helper()
+	c, err := NewCipher(testKey(seed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
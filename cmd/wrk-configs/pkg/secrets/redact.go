@@ -0,0 +1,68 @@
+package secrets
+
+// redact.go
+
+import (
+	"sort"
+	"strings"
+)
+
+// SensitiveKeys части имен ключей (без учета регистра), значения которых
+// скрываются при выводе: "password", "db_password", "AuthToken", "client_secret"
+var SensitiveKeys = []string{"password", "passwd", "secret", "token", "apikey", "api_key", "private_key"}
+
+// IsSensitive сообщает, содержит ли имя ключа одну из частей SensitiveKeys
+func IsSensitive(key string) bool {
+	key = strings.ToLower(key)
+	for _, part := range SensitiveKeys {
+		if strings.Contains(key, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// Redact возвращает копию динамической конфигурации, в которой все значения
+// под чувствительными ключами (включая вложенные объекты и массивы) заменены
+// на Redacted. Исходные данные не изменяются
+func Redact(data map[string]interface{}) map[string]interface{} {
+	return redact(data, false).(map[string]interface{})
+}
+
+// redact копирует значение; hide означает, что значение находится под чувствительным ключом
+func redact(value interface{}, hide bool) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result[key] = redact(item, hide || IsSensitive(key))
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = redact(item, hide)
+		}
+		return result
+	case Secret:
+		return v.String()
+	case nil:
+		return nil
+	}
+
+	if hide {
+		return Redacted
+	}
+	return value
+}
+
+// sortedKeys возвращает отсортированные ключи map. Копия utils.SortedKeys:
+// secrets импортируется пакетом types и не может зависеть от utils
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
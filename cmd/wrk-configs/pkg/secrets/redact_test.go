@@ -0,0 +1,124 @@
+package secrets
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestIsSensitive(t *testing.T) {
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{"password", true},
+		{"DB_PASSWORD", true},
+		{"AuthToken", true},
+		{"client_secret", true},
+		{"apiKey", true},
+		{"private_key", true},
+		{"host", false},
+		{"user", false},
+		{"tokenizer_path", true},
+	}
+	for _, tt := range tests {
+		if got := IsSensitive(tt.key); got != tt.want {
+			t.Errorf("IsSensitive(%q) = %v, ожидалось %v", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestRedact(t *testing.T) {
+	data := map[string]interface{}{
+		"database": map[string]interface{}{
+			"host":     "localhost",
+			"password": "hunter2",
+			"port":     5432,
+		},
+		"secrets": map[string]interface{}{
+			"list":  []interface{}{"a", map[string]interface{}{"x": 1}},
+			"empty": nil,
+		},
+		"tokens": []interface{}{"t1", "t2"},
+		"user":   Secret("s3cr3t"),
+	}
+	want := map[string]interface{}{
+		"database": map[string]interface{}{
+			"host":     "localhost",
+			"password": Redacted,
+			"port":     5432,
+		},
+		"secrets": map[string]interface{}{
+			"list":  []interface{}{Redacted, map[string]interface{}{"x": Redacted}},
+			"empty": nil,
+		},
+		"tokens": []interface{}{Redacted, Redacted},
+		"user":   Redacted,
+	}
+
+	got := Redact(data)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("получено %v", got)
+	}
+	if data["database"].(map[string]interface{})["password"] != "hunter2" {
+		t.Error("Redact изменил исходные данные")
+	}
+}
+
+func TestSecretFormatting(t *testing.T) {
+	s := Secret("hunter2")
+
+	for _, format := range []string{"%v", "%s", "%#v", "%+v"} {
+		if got := fmt.Sprintf(format, s); got == "" || strings.Contains(got, "hunter2") {
+			t.Errorf("Sprintf(%q) = %q", format, got)
+		}
+	}
+	if got := fmt.Sprint(struct{ P Secret }{s}); strings.Contains(got, "hunter2") {
+		t.Errorf("секрет в структуре раскрыт: %s", got)
+	}
+	if s.Reveal() != "hunter2" {
+		t.Errorf("Reveal = %q", s.Reveal())
+	}
+	if Secret("").String() != "" {
+		t.Error("пустой секрет должен выводиться пустой строкой")
+	}
+	if got := s.LogValue().String(); got != Redacted {
+		t.Errorf("LogValue = %q", got)
+	}
+}
+
+func TestSecretMarshal(t *testing.T) {
+	v := struct {
+		Password Secret `json:"password" yaml:"password"`
+	}{"hunter2"}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"password":"******"}` {
+		t.Errorf("JSON: %s", data)
+	}
+
+	data, err = yaml.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(string(data)) != `password: '******'` {
+		t.Errorf("YAML: %s", data)
+	}
+
+	// Зашифрованное значение сохраняется как есть
+	v.Password = "ENC[AES256_GCM,AAAA]"
+	data, err = json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"password":"ENC[AES256_GCM,AAAA]"}` {
+		t.Errorf("JSON: %s", data)
+	}
+}
@@ -0,0 +1,67 @@
+package secrets
+
+// secret.go
+
+import (
+	"encoding/json"
+	"log/slog"
+	"strconv"
+)
+
+// Redacted текст, которым заменяются секреты при выводе
+const Redacted = "******"
+
+// Secret строка, которая не раскрывается при выводе и сохранении:
+// fmt (%v, %s, %#v), log/slog и сериализация в JSON, YAML, TOML и INI
+// получают Redacted. Разбор из любого формата работает как для обычной строки.
+//
+// Зашифрованное значение ENC[...] сохраняется как есть: чтобы записать
+// секрет в файл, его шифруют Cipher.Encrypt и присваивают полю результат.
+// Открытое значение доступно только явно, через Reveal
+type Secret string
+
+// Reveal возвращает значение секрета
+func (s Secret) Reveal() string {
+	return string(s)
+}
+
+// String возвращает Redacted или пустую строку для пустого секрета
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return Redacted
+}
+
+// GoString используется форматом %#v
+func (s Secret) GoString() string {
+	return "secrets.Secret(" + strconv.Quote(s.String()) + ")"
+}
+
+// MarshalJSON записывает Redacted вместо значения
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.stored())
+}
+
+// MarshalYAML записывает Redacted вместо значения
+func (s Secret) MarshalYAML() (interface{}, error) {
+	return s.stored(), nil
+}
+
+// MarshalText используется TOML и INI
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(s.stored()), nil
+}
+
+// stored возвращает сохраняемое значение: ENC[...] как есть, иначе String
+func (s Secret) stored() string {
+	if IsEncrypted(string(s)) {
+		return string(s)
+	}
+	return s.String()
+}
+
+// LogValue скрывает значение в log/slog
+func (s Secret) LogValue() slog.Value {
+	return slog.StringValue(s.String())
+}
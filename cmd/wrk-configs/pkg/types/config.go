@@ -1,7 +1,12 @@
 // config.go
 package types
 
-import "time"
+import (
+	"fmt"
+	"time"
+
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/secrets"
+)
 
 // ConfigFormat представляет тип конфигурационного файла
 type ConfigFormat string
@@ -21,6 +26,24 @@ type ConfigFile struct {
 	Modified time.Time
 }
 
+// Position позиция ключа в исходном файле (строки и столбцы с 1)
+type Position struct {
+	Line   int
+	Column int
+}
+
+// IsValid сообщает, известна ли позиция
+func (p Position) IsValid() bool {
+	return p.Line > 0
+}
+
+func (p Position) String() string {
+	if !p.IsValid() {
+		return "-"
+	}
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
 // Parser интерфейс для парсеров конфигураций
 type Parser interface {
 	Parse(data []byte, v interface{}) error
@@ -41,23 +64,27 @@ type ConfigManager interface {
 	Convert(srcPath, dstPath string, dstFormat ConfigFormat) error
 }
 
-// CommonConfig базовая структура конфигурации для примеров
+// CommonConfig базовая структура конфигурации для примеров.
+// Теги validate проверяются пакетом validation (например, после ConfigManager.Load);
+// для путей, которые должны существовать, есть правила file-exists и dir-exists.
+// Пароль имеет тип secrets.Secret и не выводится ни в fmt и логах, ни в сохраняемых
+// файлах, кроме зашифрованного значения ENC[...]
 type CommonConfig struct {
 	Database struct {
-		Host     string `json:"host" yaml:"host" ini:"host"`
-		Port     int    `json:"port" yaml:"port" ini:"port"`
-		Username string `json:"username" yaml:"username" ini:"username"`
-		Password string `json:"password" yaml:"password" ini:"password"`
-	} `json:"database" yaml:"database" ini:"database"`
+		Host     string         `json:"host" yaml:"host" ini:"host" toml:"host" validate:"required,hostname|ip"`
+		Port     int            `json:"port" yaml:"port" ini:"port" toml:"port" validate:"required,min=1,max=65535"`
+		Username string         `json:"username" yaml:"username" ini:"username" toml:"username" validate:"required"`
+		Password secrets.Secret `json:"password" yaml:"password" ini:"password" toml:"password"`
+	} `json:"database" yaml:"database" ini:"database" toml:"database"`
 
 	Server struct {
-		Host string `json:"host" yaml:"host" ini:"host"`
-		Port int    `json:"port" yaml:"port" ini:"port"`
-	} `json:"server" yaml:"server" ini:"server"`
+		Host string `json:"host" yaml:"host" ini:"host" toml:"host" validate:"required,hostname|ip"`
+		Port int    `json:"port" yaml:"port" ini:"port" toml:"port" validate:"required,min=1,max=65535"`
+	} `json:"server" yaml:"server" ini:"server" toml:"server"`
 
-	Debug   bool `json:"debug" yaml:"debug" ini:"debug"`
+	Debug   bool `json:"debug" yaml:"debug" ini:"debug" toml:"debug"`
 	Logging struct {
-		Level string `json:"level" yaml:"level" ini:"level"`
-		File  string `json:"file" yaml:"file" ini:"file"`
-	} `json:"logging" yaml:"logging" ini:"logging"`
+		Level string `json:"level" yaml:"level" ini:"level" toml:"level" validate:"oneof=debug info warn error"`
+		File  string `json:"file" yaml:"file" ini:"file" toml:"file" validate:"file-exists"`
+	} `json:"logging" yaml:"logging" ini:"logging" toml:"logging"`
 }
@@ -0,0 +1,34 @@
+// errors.go
+package types
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUnsupportedFormat базовая ошибка неподдерживаемого или неопределенного формата.
+// Проверяется через errors.Is для любых *UnsupportedFormatError
+var ErrUnsupportedFormat = errors.New("неподдерживаемый формат")
+
+// UnsupportedFormatError сообщает, что для формата нет парсера или генератора,
+// либо что формат файла не удалось определить (Format пуст)
+type UnsupportedFormatError struct {
+	Format ConfigFormat
+	Path   string
+}
+
+func (e *UnsupportedFormatError) Error() string {
+	switch {
+	case e.Format == "" && e.Path != "":
+		return fmt.Sprintf("не удалось определить формат файла %s", e.Path)
+	case e.Path != "":
+		return fmt.Sprintf("%s: %q (%s)", ErrUnsupportedFormat, e.Format, e.Path)
+	default:
+		return fmt.Sprintf("%s: %q", ErrUnsupportedFormat, e.Format)
+	}
+}
+
+// Is позволяет сравнивать ошибку с ErrUnsupportedFormat
+func (e *UnsupportedFormatError) Is(target error) bool {
+	return target == ErrUnsupportedFormat
+}
@@ -0,0 +1,248 @@
+package utils
+
+// coerce.go
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Функции To* приводят значения динамической конфигурации к Go-типам.
+// Строки (INI, переменные окружения) разбираются, числа любых
+// числовых типов (float64 из JSON, int из YAML, int64 из TOML) приводятся
+// без потери точности, иначе возвращается ошибка
+
+// ToInt64 приводит значение к int64. Дробные числа считаются ошибкой
+func ToInt64(v interface{}) (int64, error) {
+	switch n := v.(type) {
+	case int:
+		return int64(n), nil
+	case int8:
+		return int64(n), nil
+	case int16:
+		return int64(n), nil
+	case int32:
+		return int64(n), nil
+	case int64:
+		return n, nil
+	case uint:
+		return uintToInt64(uint64(n))
+	case uint8:
+		return int64(n), nil
+	case uint16:
+		return int64(n), nil
+	case uint32:
+		return int64(n), nil
+	case uint64:
+		return uintToInt64(n)
+	case float32:
+		return floatToInt64(float64(n))
+	case float64:
+		return floatToInt64(n)
+	case string:
+		return strconv.ParseInt(strings.TrimSpace(n), 10, 64)
+	default:
+		return 0, fmt.Errorf("значение типа %T не является целым числом", v)
+	}
+}
+
+func uintToInt64(n uint64) (int64, error) {
+	if n > math.MaxInt64 {
+		return 0, fmt.Errorf("число %d не помещается в int64", n)
+	}
+	return int64(n), nil
+}
+
+func floatToInt64(f float64) (int64, error) {
+	if f != math.Trunc(f) {
+		return 0, fmt.Errorf("число %g не является целым", f)
+	}
+	if f < math.MinInt64 || f >= math.MaxInt64 {
+		return 0, fmt.Errorf("число %g не помещается в int64", f)
+	}
+	return int64(f), nil
+}
+
+// ToFloat64 приводит значение к float64
+func ToFloat64(v interface{}) (float64, error) {
+	switch n := v.(type) {
+	case float64:
+		return n, nil
+	case float32:
+		return float64(n), nil
+	case string:
+		return strconv.ParseFloat(strings.TrimSpace(n), 64)
+	}
+
+	i, err := ToInt64(v)
+	if err != nil {
+		return 0, fmt.Errorf("значение типа %T не является числом", v)
+	}
+	return float64(i), nil
+}
+
+// ToBool приводит значение к bool. Кроме форматов strconv.ParseBool
+// принимаются yes/no и on/off, а также числа 0 и 1
+func ToBool(v interface{}) (bool, error) {
+	switch b := v.(type) {
+	case bool:
+		return b, nil
+	case string:
+		switch strings.ToLower(strings.TrimSpace(b)) {
+		case "yes", "on":
+			return true, nil
+		case "no", "off":
+			return false, nil
+		}
+		return strconv.ParseBool(strings.TrimSpace(b))
+	}
+
+	if i, err := ToInt64(v); err == nil && (i == 0 || i == 1) {
+		return i == 1, nil
+	}
+	return false, fmt.Errorf("значение %v не является булевым", v)
+}
+
+// ToDuration приводит значение к time.Duration. Строки разбираются
+// time.ParseDuration ("1m30s"), числа и строки из цифр считаются секундами
+func ToDuration(v interface{}) (time.Duration, error) {
+	switch d := v.(type) {
+	case time.Duration:
+		return d, nil
+	case string:
+		s := strings.TrimSpace(d)
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return secondsToDuration(f)
+		}
+		return time.ParseDuration(s)
+	}
+
+	f, err := ToFloat64(v)
+	if err != nil {
+		return 0, fmt.Errorf("значение типа %T не является длительностью", v)
+	}
+	return secondsToDuration(f)
+}
+
+func secondsToDuration(f float64) (time.Duration, error) {
+	if math.Abs(f) > math.MaxInt64/float64(time.Second) {
+		return 0, fmt.Errorf("длительность %g с слишком велика", f)
+	}
+	return time.Duration(f * float64(time.Second)), nil
+}
+
+// byteUnits множители единиц размера: десятичные KB, MB... и двоичные KiB, MiB...
+var byteUnits = map[string]float64{
+	"":    1,
+	"b":   1,
+	"kb":  1e3,
+	"mb":  1e6,
+	"gb":  1e9,
+	"tb":  1e12,
+	"kib": 1 << 10,
+	"mib": 1 << 20,
+	"gib": 1 << 30,
+	"tib": 1 << 40,
+}
+
+// ParseByteSize разбирает размер вида "512", "10MB", "1.5 GiB".
+// KB, MB, GB, TB - десятичные единицы (1000), KiB, MiB, GiB, TiB - двоичные (1024).
+// Регистр единиц не учитывается
+func ParseByteSize(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	split := strings.IndexFunc(s, func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.IsSpace(r)
+	})
+	if split < 0 {
+		split = len(s)
+	}
+
+	number, unit := s[:split], strings.ToLower(strings.TrimSpace(s[split:]))
+	f, err := strconv.ParseFloat(number, 64)
+	if err != nil || f < 0 {
+		return 0, fmt.Errorf("неверный размер %q", s)
+	}
+
+	multiplier, ok := byteUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("неизвестная единица размера %q в %q", s[split:], s)
+	}
+
+	size := f * multiplier
+	if size >= math.MaxInt64 {
+		return 0, fmt.Errorf("размер %q слишком велик", s)
+	}
+	return int64(size), nil
+}
+
+// ToByteSize приводит значение к размеру в байтах: числа - байты, строки - ParseByteSize
+func ToByteSize(v interface{}) (int64, error) {
+	if s, ok := v.(string); ok {
+		return ParseByteSize(s)
+	}
+
+	n, err := ToInt64(v)
+	if err != nil {
+		return 0, fmt.Errorf("значение типа %T не является размером", v)
+	}
+	if n < 0 {
+		return 0, fmt.Errorf("отрицательный размер %d", n)
+	}
+	return n, nil
+}
+
+// timeLayouts форматы, которые принимает ToTime
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	"15:04:05.999999999",
+}
+
+// ToTime приводит значение к time.Time. Строки разбираются в форматах
+// RFC 3339, "2006-01-02 15:04:05", "2006-01-02" и "15:04:05";
+// локальные даты TOML (toml.LocalDate и др.) - по их строковому представлению
+func ToTime(v interface{}) (time.Time, error) {
+	var s string
+	switch t := v.(type) {
+	case time.Time:
+		return t, nil
+	case string:
+		s = strings.TrimSpace(t)
+	case fmt.Stringer:
+		s = t.String()
+	default:
+		return time.Time{}, fmt.Errorf("значение типа %T не является временем", v)
+	}
+
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("неверный формат времени %q", s)
+}
+
+// ToURL приводит строку к *url.URL. Адрес должен содержать схему
+func ToURL(v interface{}) (*url.URL, error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("значение типа %T не является строкой", v)
+	}
+
+	u, err := url.Parse(strings.TrimSpace(s))
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "" {
+		return nil, fmt.Errorf("в адресе %q отсутствует схема", s)
+	}
+	return u, nil
+}
@@ -0,0 +1,151 @@
+package utils
+
+// convert.go
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseLike преобразует строку (например, из переменной окружения или INI)
+// к типу значения like из динамической конфигурации.
+// Массивы задаются через запятую или в виде JSON, объекты - только JSON
+func ParseLike(s string, like interface{}) (interface{}, error) {
+	s = strings.TrimSpace(s)
+
+	switch l := like.(type) {
+	case nil, string:
+		return s, nil
+	case bool:
+		return strconv.ParseBool(s)
+	case float64:
+		return strconv.ParseFloat(s, 64)
+	case float32:
+		f, err := strconv.ParseFloat(s, 32)
+		return float32(f), err
+	case int:
+		n, err := strconv.ParseInt(s, 10, 0)
+		return int(n), err
+	case int64:
+		return strconv.ParseInt(s, 10, 64)
+	case uint64:
+		return strconv.ParseUint(s, 10, 64)
+	case time.Time:
+		return time.Parse(time.RFC3339, s)
+	case []interface{}:
+		if strings.HasPrefix(s, "[") {
+			var arr []interface{}
+			err := json.Unmarshal([]byte(s), &arr)
+			return arr, err
+		}
+		var itemLike interface{}
+		if len(l) > 0 {
+			itemLike = l[0]
+		}
+		parts := SplitList(s)
+		arr := make([]interface{}, len(parts))
+		for i, part := range parts {
+			item, err := ParseLike(part, itemLike)
+			if err != nil {
+				return nil, fmt.Errorf("элемент %d: %w", i, err)
+			}
+			arr[i] = item
+		}
+		return arr, nil
+	case map[string]interface{}:
+		var obj map[string]interface{}
+		if err := json.Unmarshal([]byte(s), &obj); err != nil {
+			return nil, fmt.Errorf("объект задается только в виде JSON: %w", err)
+		}
+		return obj, nil
+	default:
+		return nil, fmt.Errorf("неподдерживаемый тип %T", like)
+	}
+}
+
+// SetFromString записывает строковое значение в поле структуры,
+// преобразуя его к типу поля. Поддерживаются строки, числа, bool,
+// time.Duration, encoding.TextUnmarshaler, указатели и срезы (через запятую)
+func SetFromString(v reflect.Value, s string) error {
+	if v.CanAddr() {
+		if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			return u.UnmarshalText([]byte(s))
+		}
+	}
+
+	s = strings.TrimSpace(s)
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return SetFromString(v.Elem(), s)
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Type() == reflect.TypeOf(time.Duration(0)) {
+			d, err := time.ParseDuration(s)
+			if err != nil {
+				return err
+			}
+			v.SetInt(int64(d))
+			return nil
+		}
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		parts := SplitList(s)
+		slice := reflect.MakeSlice(v.Type(), len(parts), len(parts))
+		for i, part := range parts {
+			if err := SetFromString(slice.Index(i), part); err != nil {
+				return fmt.Errorf("элемент %d: %w", i, err)
+			}
+		}
+		v.Set(slice)
+	case reflect.Interface:
+		v.Set(reflect.ValueOf(s))
+	default:
+		return fmt.Errorf("неподдерживаемый тип поля %s", v.Type())
+	}
+
+	return nil
+}
+
+// SplitList разбивает список через запятую, убирая пробелы вокруг элементов
+func SplitList(s string) []string {
+	if strings.TrimSpace(s) == "" {
+		return []string{}
+	}
+	parts := strings.Split(s, ",")
+	for i, part := range parts {
+		parts[i] = strings.TrimSpace(part)
+	}
+	return parts
+}
@@ -3,6 +3,7 @@ package utils
 // finder.go
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"strings"
@@ -63,11 +64,16 @@ func GetFormatByContent(data []byte) types.ConfigFormat {
 		return ""
 	}
 
-	// JSON начинается с { или [
-	if content[0] == '{' || content[0] == '[' {
+	// JSON начинается с { или [, но [ также открывает секцию INI/TOML
+	if content[0] == '{' || (content[0] == '[' && json.Valid([]byte(content))) {
 		return types.FormatJSON
 	}
 
+	// TOML похож на INI, но значения всегда записаны литералами TOML
+	if looksLikeTOML(content) {
+		return types.FormatTOML
+	}
+
 	// INI часто содержит секции [section]
 	if strings.Contains(content, "[") && strings.Contains(content, "]") {
 		return types.FormatINI
@@ -80,3 +86,47 @@ func GetFormatByContent(data []byte) types.ConfigFormat {
 
 	return ""
 }
+
+// looksLikeTOML проверяет, что каждая строка является заголовком таблицы
+// или присваиванием, значение которого начинается как литерал TOML
+// (строка в кавычках, число, дата, булево значение, массив или inline-таблица)
+func looksLikeTOML(content string) bool {
+	assignments := 0
+
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '#' {
+			continue
+		}
+
+		if line[0] == '[' {
+			continue
+		}
+
+		eq := strings.Index(line, "=")
+		if eq < 0 && strings.ContainsRune(`"']},`, rune(line[0])) {
+			// Продолжение многострочного массива или строки
+			continue
+		}
+		if eq <= 0 {
+			return false
+		}
+
+		value := strings.TrimSpace(line[eq+1:])
+		if value == "" {
+			return false
+		}
+
+		switch {
+		case strings.ContainsRune(`"'[{+-`, rune(value[0])):
+		case value[0] >= '0' && value[0] <= '9':
+		case strings.HasPrefix(value, "true"), strings.HasPrefix(value, "false"):
+		case strings.HasPrefix(value, "inf"), strings.HasPrefix(value, "nan"):
+		default:
+			return false
+		}
+		assignments++
+	}
+
+	return assignments > 0
+}
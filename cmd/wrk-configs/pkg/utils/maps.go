@@ -0,0 +1,35 @@
+package utils
+
+// maps.go
+
+import "sort"
+
+// SortedKeys возвращает отсортированные ключи map
+func SortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// DeepCopy копирует вложенные объекты и массивы динамической конфигурации,
+// остальные значения возвращаются как есть
+func DeepCopy(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result[key] = DeepCopy(item)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = DeepCopy(item)
+		}
+		return result
+	}
+	return value
+}
@@ -0,0 +1,20 @@
+package utils
+
+// tags.go
+
+import (
+	"reflect"
+	"strings"
+)
+
+// KeyName возвращает имя ключа поля в конфигурации: из тегов json, yaml,
+// toml или ini, а при их отсутствии - имя поля. "-" означает, что поле пропускается
+func KeyName(field reflect.StructField) string {
+	for _, tagName := range []string{"json", "yaml", "toml", "ini"} {
+		name, _, _ := strings.Cut(field.Tag.Get(tagName), ",")
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
@@ -0,0 +1,134 @@
+package validation
+
+// rules.go
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	RegisterRule("min", ruleMin)
+	RegisterRule("max", ruleMax)
+	RegisterRule("oneof", ruleOneOf)
+	RegisterRule("file-exists", ruleFileExists)
+	RegisterRule("dir-exists", ruleDirExists)
+	RegisterRule("hostname", ruleHostname)
+	RegisterRule("ip", ruleIP)
+	RegisterRule("url", ruleURL)
+}
+
+// hostnamePattern имя хоста по RFC 1123
+var hostnamePattern = regexp.MustCompile(`^([a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)(\.[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*\.?$`)
+
+// ruleMin для чисел сравнивает значение, для строк, срезов и map - длину
+func ruleMin(v reflect.Value, param string) error {
+	limit, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return fmt.Errorf("неверный параметр min=%s", param)
+	}
+
+	if n, isLen, ok := measure(v); ok && n < limit {
+		if isLen {
+			return fmt.Errorf("длина %g, минимум %s", n, param)
+		}
+		return fmt.Errorf("значение %g меньше минимума %s", n, param)
+	}
+	return nil
+}
+
+// ruleMax для чисел сравнивает значение, для строк, срезов и map - длину
+func ruleMax(v reflect.Value, param string) error {
+	limit, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return fmt.Errorf("неверный параметр max=%s", param)
+	}
+
+	if n, isLen, ok := measure(v); ok && n > limit {
+		if isLen {
+			return fmt.Errorf("длина %g, максимум %s", n, param)
+		}
+		return fmt.Errorf("значение %g больше максимума %s", n, param)
+	}
+	return nil
+}
+
+// ruleOneOf проверяет, что значение входит в список через пробел
+func ruleOneOf(v reflect.Value, param string) error {
+	value := fmt.Sprint(v.Interface())
+	options := strings.Fields(param)
+	for _, option := range options {
+		if value == option {
+			return nil
+		}
+	}
+	return fmt.Errorf("значение %q не входит в допустимые [%s]", value, strings.Join(options, ", "))
+}
+
+func ruleFileExists(v reflect.Value, _ string) error {
+	path := v.String()
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("файл %s не найден", path)
+	}
+	if info.IsDir() {
+		return fmt.Errorf("%s является директорией, а не файлом", path)
+	}
+	return nil
+}
+
+func ruleDirExists(v reflect.Value, _ string) error {
+	path := v.String()
+	info, err := os.Stat(path)
+	if err != nil || !info.IsDir() {
+		return fmt.Errorf("директория %s не найдена", path)
+	}
+	return nil
+}
+
+func ruleHostname(v reflect.Value, _ string) error {
+	host := v.String()
+	if len(host) > 253 || !hostnamePattern.MatchString(host) {
+		return fmt.Errorf("%q не является именем хоста", host)
+	}
+	return nil
+}
+
+func ruleIP(v reflect.Value, _ string) error {
+	if net.ParseIP(v.String()) == nil {
+		return fmt.Errorf("%q не является IP-адресом", v.String())
+	}
+	return nil
+}
+
+func ruleURL(v reflect.Value, _ string) error {
+	u, err := url.Parse(v.String())
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("%q не является URL", v.String())
+	}
+	return nil
+}
+
+// measure возвращает число для сравнения и признак того, что это длина
+func measure(v reflect.Value) (float64, bool, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), false, true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), false, true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), false, true
+	case reflect.String:
+		return float64(len([]rune(v.String()))), true, true
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return float64(v.Len()), true, true
+	default:
+		return 0, false, false
+	}
+}
@@ -0,0 +1,197 @@
+package validation
+
+// validation.go
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/types"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/utils"
+)
+
+// TagName имя тега со списком правил, например `validate:"required,min=1,max=65535"`.
+// Правила перечисляются через запятую, альтернативы - через "|" ("hostname|ip").
+// Все правила, кроме required, пропускают нулевые значения
+const TagName = "validate"
+
+// RuleFunc проверяет значение поля; param - часть правила после "="
+type RuleFunc func(v reflect.Value, param string) error
+
+// FieldError ошибка проверки одного поля
+type FieldError struct {
+	Path    string
+	Rule    string
+	Message string
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// Errors все ошибки проверки структуры
+type Errors []*FieldError
+
+func (e Errors) Error() string {
+	lines := make([]string, len(e))
+	for i, err := range e {
+		lines[i] = "  " + err.Error()
+	}
+	return fmt.Sprintf("проверка конфигурации не пройдена (%d):\n%s", len(e), strings.Join(lines, "\n"))
+}
+
+var (
+	rulesMu sync.RWMutex
+	rules   = make(map[string]RuleFunc)
+)
+
+// RegisterRule регистрирует правило проверки; повторная регистрация заменяет правило
+func RegisterRule(name string, fn RuleFunc) {
+	rulesMu.Lock()
+	defer rulesMu.Unlock()
+	rules[name] = fn
+}
+
+// Struct проверяет структуру (или указатель на нее) по тегам validate.
+// Возвращает nil или Errors со всеми непрошедшими полями
+func Struct(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var errs Errors
+	walkStruct(rv, "", &errs)
+	if len(errs) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].Path < errs[j].Path })
+	return errs
+}
+
+// Parse разбирает данные парсером и проверяет результат
+func Parse(p types.Parser, data []byte, v interface{}) error {
+	if err := p.Parse(data, v); err != nil {
+		return err
+	}
+	return Struct(v)
+}
+
+// walkStruct обходит поля структуры
+func walkStruct(rv reflect.Value, prefix string, errs *Errors) {
+	rt := rv.Type()
+
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := utils.KeyName(field)
+		if name == "-" {
+			continue
+		}
+		path := name
+		if prefix != "" {
+			path = prefix + "." + name
+		}
+
+		value := rv.Field(i)
+		if tag := field.Tag.Get(TagName); tag != "" && tag != "-" {
+			checkField(value, path, tag, errs)
+		}
+		walkValue(value, path, errs)
+	}
+}
+
+// walkValue спускается во вложенные структуры, указатели, срезы и map
+func walkValue(v reflect.Value, path string, errs *Errors) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			walkValue(v.Elem(), path, errs)
+		}
+	case reflect.Struct:
+		walkStruct(v, path, errs)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			walkValue(v.Index(i), fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+	case reflect.Map:
+		for _, key := range v.MapKeys() {
+			walkValue(v.MapIndex(key), fmt.Sprintf("%s.%v", path, key.Interface()), errs)
+		}
+	}
+}
+
+// checkField применяет правила тега к значению поля
+func checkField(v reflect.Value, path, tag string, errs *Errors) {
+	for _, rule := range strings.Split(tag, ",") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+
+		if rule == "required" {
+			if isZero(v) {
+				*errs = append(*errs, &FieldError{Path: path, Rule: rule, Message: "обязательное значение не задано"})
+			}
+			continue
+		}
+
+		if isZero(v) {
+			continue
+		}
+
+		if err := checkAlternatives(v, rule); err != nil {
+			*errs = append(*errs, &FieldError{Path: path, Rule: rule, Message: err.Error()})
+		}
+	}
+}
+
+// checkAlternatives проверяет правило вида "a|b": достаточно одного успешного
+func checkAlternatives(v reflect.Value, rule string) error {
+	var messages []string
+
+	for _, alt := range strings.Split(rule, "|") {
+		name, param, _ := strings.Cut(alt, "=")
+
+		rulesMu.RLock()
+		fn, exists := rules[name]
+		rulesMu.RUnlock()
+		if !exists {
+			return fmt.Errorf("неизвестное правило %q", name)
+		}
+
+		err := fn(v, param)
+		if err == nil {
+			return nil
+		}
+		messages = append(messages, err.Error())
+	}
+
+	return fmt.Errorf("%s", strings.Join(messages, " или "))
+}
+
+// isZero проверяет, что значение не задано
+func isZero(v reflect.Value) bool {
+	if !v.IsValid() {
+		return true
+	}
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	default:
+		return v.IsZero()
+	}
+}
@@ -0,0 +1,172 @@
+package validation
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/parsers"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/types"
+)
+
+func TestRules(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "app.log")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		rule  string
+		value interface{}
+		ok    bool
+	}{
+		{"required", "x", true},
+		{"required", "", false},
+		{"required", []string{}, false},
+		{"required", 0, false},
+		{"min=1", 0, true}, // нулевые значения пропускаются всеми правилами, кроме required
+		{"min=1", -5, false},
+		{"max=10", 11, false},
+		{"max=10", 10.0, true},
+		{"min=3", "ab", false},
+		{"max=2", []int{1, 2, 3}, false},
+		{"min=2", "яя", true},
+		{"oneof=debug info", "info", true},
+		{"oneof=debug info", "trace", false},
+		{"hostname", "db.example.com", true},
+		{"hostname", "bad host", false},
+		{"ip", "10.0.0.1", true},
+		{"ip", "::1", true},
+		{"ip", "localhost", false},
+		{"hostname|ip", "127.0.0.1", true},
+		{"hostname|ip", "-", false},
+		{"url", "https://example.com/x", true},
+		{"url", "example.com", false},
+		{"file-exists", file, true},
+		{"file-exists", dir, false},
+		{"file-exists", filepath.Join(dir, "missing"), false},
+		{"dir-exists", dir, true},
+		{"dir-exists", file, false},
+		{"unknown", "x", false},
+	}
+	for _, tt := range tests {
+		value := reflect.ValueOf(tt.value)
+		var errs Errors
+		checkField(value, "f", tt.rule, &errs)
+		if (len(errs) == 0) != tt.ok {
+			t.Errorf("%s для %#v: ошибки = %v", tt.rule, tt.value, errs)
+		}
+	}
+}
+
+func TestStruct(t *testing.T) {
+	type server struct {
+		Host string `json:"host" validate:"required,hostname|ip"`
+		Port int    `json:"port" validate:"required,min=1,max=65535"`
+	}
+	type config struct {
+		Server   server            `json:"server"`
+		Backup   *server           `yaml:"backup"`
+		Replicas []server          `json:"replicas"`
+		Named    map[string]server `json:"named"`
+		Level    string            `json:"level" validate:"oneof=debug info"`
+		Ignored  string            `json:"-" validate:"required"`
+		hidden   string            `validate:"required"`
+	}
+
+	cfg := config{
+		Server:   server{Host: "localhost", Port: 70000},
+		Backup:   &server{},
+		Replicas: []server{{Host: "a", Port: 1}, {Host: "bad host", Port: 1}},
+		Named:    map[string]server{"x": {Host: "b"}},
+		Level:    "trace",
+	}
+
+	err := Struct(&cfg)
+	var errs Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("ожидались Errors, получено %v", err)
+	}
+
+	var paths []string
+	for _, e := range errs {
+		paths = append(paths, e.Path+" "+e.Rule)
+	}
+	want := []string{
+		"backup.host required",
+		"backup.port required",
+		"level oneof=debug info",
+		"named.x.port required",
+		"replicas[1].host hostname|ip",
+		"server.port max=65535",
+	}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("получено\n%q\nожидалось\n%q", paths, want)
+	}
+	if !strings.Contains(err.Error(), "(6)") {
+		t.Errorf("Error = %q", err.Error())
+	}
+
+	cfg = config{Server: server{Host: "localhost", Port: 80}}
+	if err := Struct(cfg); err != nil {
+		t.Errorf("корректная структура: %v", err)
+	}
+	if err := Struct((*config)(nil)); err != nil {
+		t.Errorf("nil: %v", err)
+	}
+}
+
+func TestParse(t *testing.T) {
+	var cfg struct {
+		Name string `json:"name" validate:"required"`
+	}
+	p := parsers.NewJSONParser()
+	if err := Parse(p, []byte(`{}`), &cfg); err == nil {
+		t.Error("ожидалась ошибка проверки")
+	}
+	if err := Parse(p, []byte(`{"name": "x"}`), &cfg); err != nil {
+		t.Error(err)
+	}
+	if err := Parse(p, []byte(`{`), &cfg); err == nil {
+		t.Error("ожидалась ошибка разбора")
+	}
+}
+
+func TestRegisterRule(t *testing.T) {
+	RegisterRule("even", func(v reflect.Value, _ string) error {
+		if v.Int()%2 != 0 {
+			return errors.New("нечетное число")
+		}
+		return nil
+	})
+	type config struct {
+		N int `validate:"even"`
+	}
+	if err := Struct(config{N: 3}); err == nil {
+		t.Error("ожидалась ошибка правила even")
+	}
+	if err := Struct(config{N: 4}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCommonConfig(t *testing.T) {
+	var cfg types.CommonConfig
+	cfg.Database.Host, cfg.Database.Port, cfg.Database.Username = "localhost", 5432, "app"
+	cfg.Server.Host, cfg.Server.Port = "0.0.0.0", 8080
+	cfg.Logging.Level = "info"
+	if err := Struct(&cfg); err != nil {
+		t.Errorf("без файла журнала: %v", err)
+	}
+
+	cfg.Logging.File = filepath.Join(t.TempDir(), "app.log")
+	err := Struct(&cfg)
+	var errs Errors
+	if !errors.As(err, &errs) || len(errs) != 1 || errs[0].Path != "logging.file" {
+		t.Errorf("несуществующий файл журнала: %v", err)
+	}
+}
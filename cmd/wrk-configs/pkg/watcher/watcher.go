@@ -0,0 +1,290 @@
+package watcher
+
+// watcher.go
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/parsers"
+	"github.com/KornilovLN/go-na-practike/cmd/wrk-configs/pkg/validation"
+)
+
+// DefaultInterval период опроса файлов по умолчанию
+const DefaultInterval = time.Second
+
+// Event уведомление о перезагрузке конфигурации.
+// При ошибке разбора или проверки Err заполнен, а Value остается прежним
+type Event struct {
+	Value    interface{}
+	Previous interface{}
+	Changed  []string
+	Err      error
+	Time     time.Time
+}
+
+// LoadFunc заново читает конфигурацию и возвращает новое значение
+type LoadFunc func() (interface{}, error)
+
+// ValidateFunc проверяет новое значение перед публикацией
+type ValidateFunc func(interface{}) error
+
+// Watcher опрашивает конфигурационные файлы и при изменении
+// перечитывает их, проверяет и атомарно публикует новое значение
+// подписчикам (каналы и функции обратного вызова)
+type Watcher struct {
+	Paths    []string
+	Interval time.Duration
+	Load     LoadFunc
+	Validate ValidateFunc
+
+	current atomic.Value
+
+	checkMu sync.Mutex
+	states  map[string]fileState
+
+	mu          sync.Mutex
+	subscribers []chan Event
+	callbacks   []func(Event)
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// snapshot обертка для atomic.Value (значения разных типов)
+type snapshot struct {
+	value interface{}
+}
+
+// fileState состояние файла на момент последней проверки
+type fileState struct {
+	modTime time.Time
+	size    int64
+	hash    [sha256.Size]byte
+	missing bool
+}
+
+// New создает Watcher и выполняет первую загрузку
+func New(load LoadFunc, validate ValidateFunc, paths ...string) (*Watcher, error) {
+	if load == nil {
+		return nil, errors.New("не указана функция загрузки")
+	}
+	if len(paths) == 0 {
+		return nil, errors.New("не указаны файлы для наблюдения")
+	}
+
+	w := &Watcher{
+		Paths:    paths,
+		Interval: DefaultInterval,
+		Load:     load,
+		Validate: validate,
+		states:   make(map[string]fileState),
+	}
+
+	w.scan()
+	value, err := w.reload()
+	if err != nil {
+		return nil, err
+	}
+	w.current.Store(snapshot{value})
+
+	return w, nil
+}
+
+// NewFile создает Watcher для одного файла любого поддерживаемого формата.
+// factory возвращает новый указатель на структуру или *map[string]interface{};
+// структуры проверяются по тегам validate
+func NewFile(path string, factory func() interface{}) (*Watcher, error) {
+	load := func() (interface{}, error) {
+		v := factory()
+		if err := parsers.LoadFile(path, v); err != nil {
+			return nil, err
+		}
+		return v, nil
+	}
+	return New(load, validation.Struct, path)
+}
+
+// Current возвращает последнее успешно загруженное значение
+func (w *Watcher) Current() interface{} {
+	return w.current.Load().(snapshot).value
+}
+
+// Subscribe возвращает канал событий. Канал буферизован на одно событие:
+// если подписчик не успел его прочитать, старое событие заменяется новым
+func (w *Watcher) Subscribe() <-chan Event {
+	ch := make(chan Event, 1)
+
+	w.mu.Lock()
+	w.subscribers = append(w.subscribers, ch)
+	w.mu.Unlock()
+
+	return ch
+}
+
+// OnChange регистрирует функцию, вызываемую для каждого события
+func (w *Watcher) OnChange(fn func(Event)) {
+	w.mu.Lock()
+	w.callbacks = append(w.callbacks, fn)
+	w.mu.Unlock()
+}
+
+// Start запускает опрос файлов в отдельной горутине
+func (w *Watcher) Start() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.stop != nil {
+		return
+	}
+	w.stop = make(chan struct{})
+	w.done = make(chan struct{})
+
+	go w.run(w.stop, w.done)
+}
+
+// Stop останавливает опрос и закрывает каналы подписчиков
+func (w *Watcher) Stop() {
+	w.mu.Lock()
+	stop, done := w.stop, w.done
+	w.stop = nil
+	w.mu.Unlock()
+
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-done
+
+	w.mu.Lock()
+	for _, ch := range w.subscribers {
+		close(ch)
+	}
+	w.subscribers = nil
+	w.mu.Unlock()
+}
+
+// Check проверяет файлы один раз и при изменении перезагружает конфигурацию.
+// Возвращает true, если изменения были обнаружены
+func (w *Watcher) Check() bool {
+	w.checkMu.Lock()
+	defer w.checkMu.Unlock()
+
+	changed := w.scan()
+	if len(changed) == 0 {
+		return false
+	}
+
+	previous := w.Current()
+	event := Event{Previous: previous, Changed: changed, Time: time.Now()}
+
+	value, err := w.reload()
+	if err != nil {
+		event.Value = previous
+		event.Err = err
+	} else {
+		w.current.Store(snapshot{value})
+		event.Value = value
+	}
+
+	w.publish(event)
+	return true
+}
+
+// run цикл опроса
+func (w *Watcher) run(stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+
+	interval := w.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			w.Check()
+		}
+	}
+}
+
+// reload загружает и проверяет новое значение
+func (w *Watcher) reload() (interface{}, error) {
+	value, err := w.Load()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка загрузки конфигурации: %w", err)
+	}
+	if w.Validate != nil {
+		if err := w.Validate(value); err != nil {
+			return nil, fmt.Errorf("новая конфигурация не прошла проверку: %w", err)
+		}
+	}
+	return value, nil
+}
+
+// scan сравнивает текущее состояние файлов с сохраненным.
+// Время изменения и размер проверяются всегда, содержимое - только
+// если они изменились, чтобы не перезагружать конфигурацию после touch
+func (w *Watcher) scan() []string {
+	var changed []string
+
+	for _, path := range w.Paths {
+		old, known := w.states[path]
+		state := fileState{}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			state.missing = true
+		} else {
+			state.modTime = info.ModTime()
+			state.size = info.Size()
+			state.hash = old.hash
+			if !known || old.missing || !state.modTime.Equal(old.modTime) || state.size != old.size {
+				if data, err := os.ReadFile(path); err == nil {
+					state.hash = sha256.Sum256(data)
+				}
+			}
+		}
+
+		w.states[path] = state
+		if known && (state.missing != old.missing || state.hash != old.hash) {
+			changed = append(changed, path)
+		}
+	}
+
+	return changed
+}
+
+// publish рассылает событие подписчикам, не блокируясь на медленных каналах
+func (w *Watcher) publish(event Event) {
+	w.mu.Lock()
+	for _, ch := range w.subscribers {
+		for sent := false; !sent; {
+			select {
+			case ch <- event:
+				sent = true
+			default:
+				// Выбрасываем непрочитанное старое событие
+				select {
+				case <-ch:
+				default:
+				}
+			}
+		}
+	}
+	callbacks := append(([]func(Event))(nil), w.callbacks...)
+	w.mu.Unlock()
+
+	for _, fn := range callbacks {
+		fn(event)
+	}
+}
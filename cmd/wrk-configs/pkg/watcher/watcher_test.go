@@ -0,0 +1,153 @@
+package watcher
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type config struct {
+	Name string `json:"name" validate:"required"`
+	Port int    `json:"port"`
+}
+
+// writeFile записывает файл и сдвигает время изменения,
+// чтобы изменение было видно при грубом разрешении времени ФС
+func writeFile(t *testing.T, path, data string, age time.Duration) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	mtime := time.Now().Add(-age)
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCheckReloads(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.json")
+	writeFile(t, path, `{"name": "a", "port": 1}`, time.Hour)
+
+	w, err := NewFile(path, func() interface{} { return &config{} })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg := w.Current().(*config); cfg.Name != "a" || cfg.Port != 1 {
+		t.Fatalf("Current = %+v", cfg)
+	}
+
+	var events []Event
+	w.OnChange(func(e Event) { events = append(events, e) })
+	ch := w.Subscribe()
+
+	if w.Check() {
+		t.Error("Check без изменений вернул true")
+	}
+
+	// touch без изменения содержимого не перезагружает конфигурацию
+	now := time.Now()
+	if err := os.Chtimes(path, now, now); err != nil {
+		t.Fatal(err)
+	}
+	if w.Check() {
+		t.Error("Check после touch вернул true")
+	}
+
+	writeFile(t, path, `{"name": "b", "port": 2}`, 0)
+	if !w.Check() {
+		t.Fatal("Check не обнаружил изменение")
+	}
+	if cfg := w.Current().(*config); cfg.Name != "b" || cfg.Port != 2 {
+		t.Errorf("Current = %+v", cfg)
+	}
+
+	select {
+	case e := <-ch:
+		if e.Err != nil || e.Previous.(*config).Name != "a" || len(e.Changed) != 1 || e.Changed[0] != path {
+			t.Errorf("событие = %+v", e)
+		}
+	default:
+		t.Error("подписчик не получил событие")
+	}
+	if len(events) != 1 {
+		t.Errorf("OnChange вызван %d раз", len(events))
+	}
+}
+
+func TestCheckKeepsValueOnError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.json")
+	writeFile(t, path, `{"name": "a"}`, time.Hour)
+
+	w, err := NewFile(path, func() interface{} { return &config{} })
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch := w.Subscribe()
+
+	for _, data := range []string{`{"name": ""}`, `{"name": `} {
+		writeFile(t, path, data, 0)
+		if !w.Check() {
+			t.Fatalf("%s: изменение не обнаружено", data)
+		}
+		e := <-ch
+		if e.Err == nil {
+			t.Errorf("%s: ожидалась ошибка", data)
+		}
+		if cfg := w.Current().(*config); cfg.Name != "a" || e.Value != e.Previous {
+			t.Errorf("%s: значение заменено: %+v", data, cfg)
+		}
+	}
+
+	// Удаление файла тоже изменение
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if !w.Check() {
+		t.Error("удаление файла не обнаружено")
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	load := func() (interface{}, error) { return nil, errors.New("boom") }
+	if _, err := New(nil, nil, "x"); err == nil {
+		t.Error("без функции загрузки: ожидалась ошибка")
+	}
+	if _, err := New(load, nil); err == nil {
+		t.Error("без файлов: ожидалась ошибка")
+	}
+	if _, err := New(load, nil, "x"); err == nil {
+		t.Error("ошибка первой загрузки: ожидалась ошибка")
+	}
+}
+
+func TestStartStop(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.json")
+	writeFile(t, path, `{"name": "a"}`, time.Hour)
+
+	w, err := NewFile(path, func() interface{} { return &config{} })
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Interval = 5 * time.Millisecond
+	ch := w.Subscribe()
+	w.Start()
+	w.Start()
+
+	writeFile(t, path, `{"name": "bb"}`, 0)
+	select {
+	case e := <-ch:
+		if e.Err != nil || e.Value.(*config).Name != "bb" {
+			t.Errorf("событие = %+v", e)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("событие не получено")
+	}
+
+	w.Stop()
+	w.Stop()
+	if _, ok := <-ch; ok {
+		t.Error("канал подписчика не закрыт после Stop")
+	}
+}
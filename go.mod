@@ -3,10 +3,12 @@ module github.com/KornilovLN/go-na-practike
 go 1.23.1
 
 require (
-    gopkg.in/urfave/cli.v1 v1.20.0
-	github.com/kylelemons/go-gypsy v1.0.0 // indirect
-	gopkg.in/gcfg.v1 v1.2.3 // indirect
-	gopkg.in/ini.v1 v1.67.0 // indirect
-	gopkg.in/warnings.v0 v0.1.2 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
+	github.com/kylelemons/go-gypsy v1.0.0
+	github.com/pelletier/go-toml/v2 v2.2.4
+	gopkg.in/gcfg.v1 v1.2.3
+	gopkg.in/ini.v1 v1.67.0
+	gopkg.in/urfave/cli.v1 v1.20.0
+	gopkg.in/yaml.v3 v3.0.1
 )
+
+require gopkg.in/warnings.v0 v0.1.2 // indirect